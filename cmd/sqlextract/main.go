@@ -1,16 +1,31 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"cloud.google.com/go/bigquery"
+
+	"github.com/gerhard-ee/sqlextract/internal/catalog"
+	"github.com/gerhard-ee/sqlextract/internal/checkpoint"
 	"github.com/gerhard-ee/sqlextract/internal/config"
+	"github.com/gerhard-ee/sqlextract/internal/daemon"
 	"github.com/gerhard-ee/sqlextract/internal/database"
+	"github.com/gerhard-ee/sqlextract/internal/extractor"
+	"github.com/gerhard-ee/sqlextract/internal/ingest"
+	"github.com/gerhard-ee/sqlextract/internal/metrics"
+	"github.com/gerhard-ee/sqlextract/internal/migrate"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
 	"github.com/gerhard-ee/sqlextract/internal/state"
+	"github.com/gerhard-ee/sqlextract/internal/verify"
 )
 
 var (
@@ -31,6 +46,7 @@ var (
 	sfAccount   = flag.String("account", "", "Snowflake account identifier")
 	sfWarehouse = flag.String("warehouse", "", "Snowflake warehouse name")
 	sfRole      = flag.String("role", "", "Snowflake role name")
+	sfAsync     = flag.Bool("async", false, "Use Snowflake asynchronous query mode (crash-safe, resumable via query ID)")
 
 	// Databricks specific flags
 	dbWorkspace = flag.String("workspace", "", "Databricks workspace URL")
@@ -42,12 +58,53 @@ var (
 	output       = flag.String("output", "", "Output file path (CSV or Parquet)")
 	outputFormat = flag.String("format", "csv", "Output format (csv or parquet)")
 	batchSize    = flag.Int("batch-size", 1000, "Number of rows to extract in each batch")
+	batchTimeout = flag.Int("batch-timeout", 0, "Seconds a single ExtractBatch call is allowed to run before its context is canceled (0 disables the per-batch timeout)")
 	keyColumns   = flag.String("keys", "", "Comma-separated list of key columns for pagination")
 	whereClause  = flag.String("where", "", "SQL WHERE clause for filtering data")
+	resumeMode   = flag.String("resume-mode", "auto", "Pagination/resume strategy for ExtractBatch: offset, keyset, or auto (keyset when -keys is set)")
 
 	// State management flags
-	namespace = flag.String("namespace", "default", "Kubernetes namespace for state management")
-	stateType = flag.String("state-type", "memory", "State management type (memory or kubernetes)")
+	namespace     = flag.String("namespace", "default", "Kubernetes namespace for state management")
+	stateType     = flag.String("state-type", "memory", "State management type (memory, kubernetes, postgres, redis, bolt, or etcd)")
+	stateDSN      = flag.String("state-dsn", "", "Postgres connection string (used with -state-type=postgres)")
+	redisAddr     = flag.String("redis-addr", "localhost:6379", "Redis address (used with -state-type=redis)")
+	redisPassword = flag.String("redis-password", "", "Redis password (used with -state-type=redis)")
+	redisDB       = flag.Int("redis-db", 0, "Redis logical database number (used with -state-type=redis)")
+	boltPath      = flag.String("bolt-path", "sqlextract-state.db", "BoltDB file path (used with -state-type=bolt)")
+	etcdEndpoints = flag.String("etcd-endpoints", "localhost:2379", "Comma-separated etcd endpoints (used with -state-type=etcd)")
+
+	// Server-side bulk unload flags
+	unload      = flag.Bool("unload", false, "Use server-side bulk unload (Snowflake COPY INTO / BigQuery EXTRACT job) instead of paginated SELECTs")
+	unloadStage = flag.String("unload-stage", "@~/sqlextract", "Snowflake stage to COPY INTO before downloading (used with -unload)")
+	gcsBucket   = flag.String("gcs-bucket", "", "GCS destination URI for a BigQuery EXTRACT job, e.g. gs://bucket/path (used with -unload)")
+
+	// Post-extract verification
+	verifyFlag = flag.Bool("verify", false, "Re-verify the manifest saved for -table by recomputing its source checksum, instead of extracting")
+
+	// Change-data-capture extraction
+	cdc       = flag.Bool("cdc", false, "Extract only rows changed since the last call (Postgres logical replication, MSSQL CDC), instead of a full table scan")
+	cdcSource = flag.String("cdc-source", "", "Change feed to read for -cdc: a replication slot name (Postgres) or CDC capture instance (MSSQL)")
+
+	// Watermark-based incremental extraction (any database type)
+	watermarkColumn = flag.String("watermark-column", "", "Extract only rows where this column is greater than the high watermark from the previous run (any database type), instead of a full table scan; requires -checkpoint-store")
+	watermarkSince  = flag.String("watermark-since", "", "Initial watermark value for -watermark-column's first run; ignored once a watermark has been checkpointed")
+
+	// Parallel sharded extraction
+	parallelism     = flag.Int("parallelism", 1, "Number of shards to extract concurrently via internal/extractor; each shard writes its own <output>.shardN.<format> file. 1 disables sharding")
+	shardStrategy   = flag.String("shard-strategy", "range", "How -parallelism splits the table into shards: range (numeric key, MIN/MAX partitioned), hash (composite or string key), or ntile (even row count per shard)")
+	jobID           = flag.String("job-id", "", "Job ID for shard state rows when -parallelism > 1, so multiple processes started with the same -job-id cooperatively drain the table (default: -table)")
+	checkpointStore = flag.String("checkpoint-store", "", "checkpoint.Store URI to record per-chunk checkpoints under during -parallelism > 1 extraction: a directory, sqlite://path, s3://bucket/prefix, or gs://bucket/prefix (disabled unless set; inspect with the `sqlextract checkpoint` subcommand)")
+
+	// Post-extract catalog registration
+	registerGlue  = flag.Bool("register-glue", false, "Register -table's schema and output location with a table catalog after a successful extract, so it's queryable through Athena/Spark")
+	glueDatabase  = flag.String("glue-database", "", "Catalog database name to register the table under (used with -register-glue)")
+	glueLocalDir  = flag.String("glue-local-dir", "", "Write the catalog entry as a local _schema.json sidecar under this directory instead of calling AWS Glue (used with -register-glue, for offline runs)")
+	gluePartition = flag.String("glue-partitions", "", "Comma-separated list of partition columns to register for -table (used with -register-glue)")
+
+	// Observability flags
+	metricsAddr    = flag.String("metrics-addr", "", "Address (e.g. :9090) to serve Prometheus /metrics on; disabled unless set")
+	pushgatewayURL = flag.String("pushgateway-url", "", "Prometheus Pushgateway URL to push final metric values to on exit; disabled unless set")
+	pushgatewayJob = flag.String("pushgateway-job", "sqlextract", "Job name to group pushed metrics under (used with -pushgateway-url)")
 
 	// Help flag
 	showHelp = flag.Bool("help", false, "Show detailed help information")
@@ -68,9 +125,34 @@ func initFlags() {
 	if *table == "" {
 		log.Fatal("Table name is required. Use -help for more information.")
 	}
-	if *output == "" {
+	if *output == "" && !*verifyFlag {
 		log.Fatal("Output file path is required. Use -help for more information.")
 	}
+	switch *resumeMode {
+	case "offset", "auto":
+	case "keyset":
+		if *keyColumns == "" {
+			log.Fatal("-resume-mode=keyset requires -keys to be set. Use -help for more information.")
+		}
+	default:
+		log.Fatalf("Unsupported -resume-mode: %s. Use -help for more information.", *resumeMode)
+	}
+	if *cdc && *cdcSource == "" {
+		log.Fatal("-cdc requires -cdc-source to be set. Use -help for more information.")
+	}
+	if *watermarkColumn != "" && *checkpointStore == "" {
+		log.Fatal("-watermark-column requires -checkpoint-store to be set. Use -help for more information.")
+	}
+	if *parallelism > 1 {
+		if *keyColumns == "" {
+			log.Fatal("-parallelism > 1 requires -keys to be set. Use -help for more information.")
+		}
+		switch *shardStrategy {
+		case "range", "hash", "ntile":
+		default:
+			log.Fatalf("Unsupported -shard-strategy: %s. Use -help for more information.", *shardStrategy)
+		}
+	}
 
 	// Validate database-specific required flags
 	switch *dbType {
@@ -130,22 +212,749 @@ func printHelp() {
 	fmt.Printf("%s%s%s\n", headerColor, string(helpText), resetColor)
 }
 
-func main() {
-	initFlags()
+// runMigrate handles the `sqlextract migrate up|down|goto|steps|force|version`
+// subcommand. It uses its own flag.FlagSet (over os.Args[2:]) since the
+// migrate subcommand only needs a subset of the top-level connection flags
+// plus a migrations directory.
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	mDBType := fs.String("type", "", "Database type (postgres, mssql, bigquery, snowflake, databricks)")
+	mHost := fs.String("host", "", "Database host")
+	mPort := fs.Int("port", 0, "Database port")
+	mUser := fs.String("user", "", "Database user")
+	mPassword := fs.String("password", "", "Database password")
+	mDBName := fs.String("database", "", "Database name")
+	mSchema := fs.String("schema", "", "Database schema (optional)")
+	mProjectID := fs.String("project", "", "Google Cloud project ID (required for BigQuery)")
+	mAccount := fs.String("account", "", "Snowflake account identifier")
+	mWarehouse := fs.String("warehouse", "", "Snowflake warehouse name")
+	mRole := fs.String("role", "", "Snowflake role name")
+	mDir := fs.String("dir", "migrations", "Migrations directory")
+	mNamespace := fs.String("namespace", "default", "Kubernetes namespace for state management")
+	mStateType := fs.String("state-type", "memory", "State management type (memory, kubernetes, postgres, redis, bolt, or etcd)")
+	mStateDSN := fs.String("state-dsn", "", "Postgres connection string (used with -state-type=postgres)")
+	mRedisAddr := fs.String("redis-addr", "localhost:6379", "Redis address (used with -state-type=redis)")
+	mRedisPassword := fs.String("redis-password", "", "Redis password (used with -state-type=redis)")
+	mRedisDB := fs.Int("redis-db", 0, "Redis logical database number (used with -state-type=redis)")
+	mBoltPath := fs.String("bolt-path", "sqlextract-state.db", "BoltDB file path (used with -state-type=bolt)")
+	mEtcdEndpoints := fs.String("etcd-endpoints", "localhost:2379", "Comma-separated etcd endpoints (used with -state-type=etcd)")
+	mGoto := fs.Int("version", 0, "Target version for the goto and force commands")
+	mSteps := fs.Int("steps", 0, "Number of migrations to step for the steps command (negative rolls back)")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		log.Fatal("usage: sqlextract migrate <up|down|goto|steps|force|version> [flags]")
+	}
+	action := fs.Arg(0)
+
+	var stateManager state.Manager
+	var err error
+	switch *mStateType {
+	case "kubernetes":
+		stateManager, err = state.NewKubernetesManager(*mNamespace, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create Kubernetes state manager: %v", err)
+		}
+	case "postgres":
+		stateManager, err = state.NewPostgresManager(*mStateDSN, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create Postgres state manager: %v", err)
+		}
+	case "redis":
+		stateManager = state.NewRedisManager(*mRedisAddr, *mRedisPassword, *mRedisDB, processOwnerID())
+	case "bolt":
+		stateManager, err = state.NewBoltManager(*mBoltPath, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create BoltDB state manager: %v", err)
+		}
+	case "etcd":
+		stateManager, err = state.NewEtcdManager(state.ParseEndpoints(*mEtcdEndpoints), *mNamespace, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create etcd state manager: %v", err)
+		}
+	default:
+		stateManager = state.NewMemoryManager()
+	}
+
+	cfg := &config.Config{
+		Type:      *mDBType,
+		Host:      *mHost,
+		Port:      *mPort,
+		User:      *mUser,
+		Password:  *mPassword,
+		Database:  *mDBName,
+		Schema:    *mSchema,
+		ProjectID: *mProjectID,
+		Account:   *mAccount,
+		Warehouse: *mWarehouse,
+		Role:      *mRole,
+	}
+
+	ctx := context.Background()
+
+	db, err := database.NewDatabase(*mDBType, cfg, stateManager)
+	if err != nil {
+		log.Fatalf("Failed to create database instance: %v", err)
+	}
+	if err := db.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close(ctx)
+
+	runner := migrate.NewRunner(db, stateManager, *mDBType, *mDir)
+
+	switch action {
+	case "up":
+		if err := runner.Up(ctx); err != nil {
+			log.Fatalf("migrate up failed: %v", err)
+		}
+	case "down":
+		if err := runner.Down(ctx); err != nil {
+			log.Fatalf("migrate down failed: %v", err)
+		}
+	case "goto":
+		if err := runner.Goto(ctx, *mGoto); err != nil {
+			log.Fatalf("migrate goto failed: %v", err)
+		}
+	case "steps":
+		if err := runner.Steps(ctx, *mSteps); err != nil {
+			log.Fatalf("migrate steps failed: %v", err)
+		}
+	case "force":
+		if err := runner.Force(ctx, *mGoto); err != nil {
+			log.Fatalf("migrate force failed: %v", err)
+		}
+	case "version":
+		version, dirty, err := runner.Version(ctx)
+		if err != nil {
+			log.Fatalf("failed to read migration version: %v", err)
+		}
+		if dirty {
+			log.Printf("current migration version: %d (dirty - resolve with `migrate force -version=N`)", version)
+		} else {
+			log.Printf("current migration version: %d", version)
+		}
+	default:
+		log.Fatalf("unknown migrate action: %s", action)
+	}
+}
+
+// runCheckpoint handles the `sqlextract checkpoint` subcommand: dump,
+// restore, and error-destroy operations against a checkpoint.Store,
+// mirroring tidb-lightning-ctl's checkpoint inspection/repair commands.
+func runCheckpoint(args []string) {
+	fs := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	cStore := fs.String("store", "./checkpoints", "checkpoint.Store URI: a directory (local JSON files), sqlite://path, s3://bucket/prefix, or gs://bucket/prefix")
+	cJobID := fs.String("job-id", "", "Job ID the checkpoints were recorded under")
+	cTable := fs.String("table", "", "Table the checkpoints were recorded for")
+	cChunkID := fs.String("chunk-id", "", "Chunk ID (used with error-destroy)")
+	cOutputFile := fs.String("output-file", "", "Partial output file to delete (used with error-destroy)")
+	cIn := fs.String("in", "", "JSON file of []*checkpoint.Chunk to load (used with restore)")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		log.Fatal("usage: sqlextract checkpoint <dump|restore|error-destroy> [flags]")
+	}
+	action := fs.Arg(0)
+
+	if *cJobID == "" || *cTable == "" {
+		log.Fatal("-job-id and -table are required. Use -help for more information.")
+	}
+
+	store, err := checkpoint.NewStore(*cStore)
+	if err != nil {
+		log.Fatalf("Failed to open checkpoint store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	switch action {
+	case "dump":
+		chunks, err := checkpoint.Dump(ctx, store, *cJobID, *cTable)
+		if err != nil {
+			log.Fatalf("checkpoint dump failed: %v", err)
+		}
+		data, err := json.MarshalIndent(chunks, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal checkpoints: %v", err)
+		}
+		fmt.Println(string(data))
+	case "restore":
+		if *cIn == "" {
+			log.Fatal("restore requires -in to be set. Use -help for more information.")
+		}
+		data, err := os.ReadFile(*cIn)
+		if err != nil {
+			log.Fatalf("failed to read %s: %v", *cIn, err)
+		}
+		var chunks []*checkpoint.Chunk
+		if err := json.Unmarshal(data, &chunks); err != nil {
+			log.Fatalf("failed to unmarshal %s: %v", *cIn, err)
+		}
+		if err := checkpoint.Restore(ctx, store, chunks); err != nil {
+			log.Fatalf("checkpoint restore failed: %v", err)
+		}
+		log.Printf("restored %d checkpoint(s)", len(chunks))
+	case "error-destroy":
+		if *cChunkID == "" {
+			log.Fatal("error-destroy requires -chunk-id to be set. Use -help for more information.")
+		}
+		if err := checkpoint.ErrorDestroy(ctx, store, *cJobID, *cTable, *cChunkID, *cOutputFile); err != nil {
+			log.Fatalf("checkpoint error-destroy failed: %v", err)
+		}
+		log.Printf("destroyed checkpoint and partial output for chunk %s", *cChunkID)
+	default:
+		log.Fatalf("unknown checkpoint action: %s", action)
+	}
+}
+
+// runIngest handles the `sqlextract ingest` subcommand: it generates an
+// ingestion script for -type and prints it to stdout for an operator to
+// review or pipe into a worksheet, instead of running it. Passing
+// -merge-keys switches from the default INSERT OVERWRITE script to a
+// Delta Lake MERGE INTO upsert, for ingesters that support it.
+func runIngest(args []string) {
+	fs := flag.NewFlagSet("ingest", flag.ExitOnError)
+	iDBType := fs.String("type", "", "Database type (snowflake, bigquery, databricks, postgres, mssql)")
+	iFormat := fs.String("format", "csv", "Source format for the generated script (csv or parquet); ignored when -merge-keys is set")
+	iSource := fs.String("source", "", "Source file or stage path to ingest from")
+	iTarget := fs.String("target", "", "Target table to ingest into")
+	iMergeKeys := fs.String("merge-keys", "", "Comma-separated key columns; when set, generates a Delta MERGE INTO upsert instead of an INSERT OVERWRITE (requires a DeltaMerger-capable -type, e.g. databricks)")
+	iEvolveSchema := fs.Bool("evolve-schema", false, "Enable Delta schema auto-merge in the generated MERGE script (used with -merge-keys)")
+	iSoftDeleteColumn := fs.String("soft-delete-column", "", "Boolean source column; matched rows with it true are deleted instead of updated (used with -merge-keys)")
+	iPartitionBy := fs.String("partition-by", "", "Comma-separated target partition columns to add to the MERGE's ON clause as a pruning hint (used with -merge-keys)")
+	iZOrderBy := fs.String("z-order-by", "", "Comma-separated columns to OPTIMIZE ZORDER BY after the MERGE (used with -merge-keys)")
+	iCreateIfNotExists := fs.Bool("create-if-not-exists", false, "Wrap the MERGE with CREATE TABLE IF NOT EXISTS ... USING DELTA (used with -merge-keys)")
+	iExecute := fs.Bool("execute", false, "Run the load directly against -target through the target engine's native bulk path, instead of printing the generated script (requires the connection flags below; not supported with -merge-keys)")
+	iTruncate := fs.Bool("truncate", false, "With -execute, clear -target before loading instead of appending to it")
+	iHost := fs.String("host", "", "Target database host (used with -execute)")
+	iPort := fs.Int("port", 0, "Target database port (used with -execute)")
+	iUser := fs.String("user", "", "Target database user (used with -execute)")
+	iPassword := fs.String("password", "", "Target database password, or Databricks access token (used with -execute)")
+	iDatabase := fs.String("database", "", "Target database name (used with -execute)")
+	iSchema := fs.String("schema", "", "Target database schema (used with -execute)")
+	iAccount := fs.String("account", "", "Snowflake account identifier (used with -execute -type snowflake)")
+	iWarehouse := fs.String("warehouse", "", "Snowflake warehouse name (used with -execute -type snowflake)")
+	iCatalog := fs.String("catalog", "", "Databricks catalog name (used with -execute -type databricks)")
+	iProject := fs.String("project", "", "Google Cloud project ID (used with -execute -type bigquery)")
+	fs.Parse(args)
+
+	if *iSource == "" || *iTarget == "" {
+		log.Fatal("usage: sqlextract ingest -type <type> -source <path> -target <table> [-merge-keys k1,k2 | -format csv|parquet | -execute]")
+	}
+
+	ingester, err := ingest.NewIngester(*iDBType)
+	if err != nil {
+		log.Fatalf("Failed to create ingester: %v", err)
+	}
+
+	if *iExecute {
+		if *iMergeKeys != "" {
+			log.Fatal("-execute is not supported with -merge-keys")
+		}
+		cfg := &config.Config{
+			Type:      *iDBType,
+			Host:      *iHost,
+			Port:      *iPort,
+			User:      *iUser,
+			Password:  *iPassword,
+			Database:  *iDatabase,
+			Schema:    *iSchema,
+			Account:   *iAccount,
+			Warehouse: *iWarehouse,
+			Catalog:   *iCatalog,
+			ProjectID: *iProject,
+		}
+		runIngestExecute(ingester, *iDBType, cfg, *iSource, *iTarget, *iFormat, *iTruncate)
+		return
+	}
+
+	var script string
+	if *iMergeKeys != "" {
+		merger, ok := ingester.(ingest.DeltaMerger)
+		if !ok {
+			log.Fatalf("-merge-keys is not supported for -type %s", *iDBType)
+		}
+		opts := ingest.MergeOptions{
+			EvolveSchema:      *iEvolveSchema,
+			SoftDeleteColumn:  *iSoftDeleteColumn,
+			CreateIfNotExists: *iCreateIfNotExists,
+		}
+		if *iPartitionBy != "" {
+			opts.PartitionBy = strings.Split(*iPartitionBy, ",")
+		}
+		if *iZOrderBy != "" {
+			opts.ZOrderBy = strings.Split(*iZOrderBy, ",")
+		}
+		script, err = merger.GenerateDeltaMergeScript(*iSource, *iTarget, strings.Split(*iMergeKeys, ","), opts)
+	} else {
+		switch *iFormat {
+		case "csv":
+			script, err = ingester.GenerateCSVIngestScript(*iSource, *iTarget)
+		case "parquet":
+			script, err = ingester.GenerateParquetIngestScript(*iSource, *iTarget)
+		default:
+			log.Fatalf("Unsupported -format: %s", *iFormat)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to generate ingestion script: %v", err)
+	}
+
+	fmt.Println(script)
+}
+
+// runIngestExecute runs the load straight through ingester's native bulk
+// path - BulkLoader over a *sql.DB for every engine but BigQuery,
+// BigQueryBulkLoader over a *bigquery.Client for it - instead of printing
+// a script for an operator to run by hand, so "extract from one system
+// and load into <target>" is something the shipped binary can do in one
+// step.
+func runIngestExecute(ingester ingest.Ingester, dbType string, cfg *config.Config, sourcePath, targetTable, format string, truncate bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	source := ingest.Source{Path: sourcePath}
+	target := ingest.Target{Table: targetTable, Format: format, Truncate: truncate}
+
+	if bqLoader, ok := ingester.(ingest.BigQueryBulkLoader); ok {
+		client, err := bigquery.NewClient(ctx, cfg.ProjectID)
+		if err != nil {
+			log.Fatalf("Failed to create BigQuery client: %v", err)
+		}
+		defer client.Close()
+
+		result, err := bqLoader.Execute(ctx, client, source, target)
+		if err != nil {
+			log.Fatalf("Ingest execution failed: %v", err)
+		}
+		log.Printf("Ingest complete: %d rows (%d bytes) loaded into %s, %d rejected", result.RowsLoaded, result.BytesLoaded, targetTable, result.RowsRejected)
+		return
+	}
+
+	loader, ok := ingester.(ingest.BulkLoader)
+	if !ok {
+		log.Fatalf("-execute is not supported for -type %s", dbType)
+	}
+
+	db, err := openIngestTarget(dbType, cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to target database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := loader.Execute(ctx, db, source, target)
+	if err != nil {
+		log.Fatalf("Ingest execution failed: %v", err)
+	}
+	log.Printf("Ingest complete: %d rows (%d bytes) loaded into %s, %d rejected", result.RowsLoaded, result.BytesLoaded, targetTable, result.RowsRejected)
+}
+
+// openIngestTarget opens a *sql.DB against cfg using the same DSN format
+// and driver each internal/database connector builds for dbType, since
+// ingest's BulkLoader implementations expect the same driver-native
+// connection internal/database would hand them.
+func openIngestTarget(dbType string, cfg *config.Config) (*sql.DB, error) {
+	switch dbType {
+	case "postgres":
+		connStr := fmt.Sprintf(
+			"host=%s port=%d dbname=%s user=%s password=%s sslmode=disable",
+			cfg.Host, cfg.Port, cfg.Database, cfg.User, cfg.Password,
+		)
+		return sql.Open("postgres", connStr)
+	case "mssql":
+		connStr := fmt.Sprintf(
+			"sqlserver://%s:%s@%s:%d?database=%s",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database,
+		)
+		return sql.Open("sqlserver", connStr)
+	case "snowflake":
+		connStr := fmt.Sprintf(
+			"%s:%s@%s/%s/%s?warehouse=%s",
+			cfg.User, cfg.Password, cfg.Host, cfg.Database, cfg.Schema, cfg.Warehouse,
+		)
+		return sql.Open("snowflake", connStr)
+	case "databricks":
+		connStr := fmt.Sprintf(
+			"databricks://token:%s@%s:443/%s?catalog=%s&schema=%s",
+			cfg.Password, cfg.Host, cfg.Database, cfg.Catalog, cfg.Schema,
+		)
+		return sql.Open("databricks", connStr)
+	default:
+		return nil, fmt.Errorf("unsupported database type for -execute: %s", dbType)
+	}
+}
+
+// processOwnerID identifies this process to the leader-election style
+// leases PostgresManager and RedisManager take out in LockState, so a
+// renewal by this same process can be told apart from a lease held by a
+// different replica.
+func processOwnerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// runVerify re-checksums *table on the source via db.ChecksumTable and
+// compares it against the SourceChecksum recorded in the manifest that
+// ExtractData saved through stateManager during the last extraction,
+// using the same keyColumns/whereClause that extraction used so the two
+// checksums are computed over the same rows. It then runs
+// verify.VerifyExtract against each part's output file, an independent
+// check that the bytes on disk still match what was written batch by
+// batch, which catches truncation or corruption that a source-side
+// recheck alone wouldn't.
+func runVerify(ctx context.Context, db database.Database, stateManager state.Manager, table string) error {
+	manifest, err := stateManager.GetManifest(ctx, table)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %v", err)
+	}
+	if manifest == nil {
+		return fmt.Errorf("no manifest found for table %s - run an extraction first", table)
+	}
+
+	checksum, err := db.ChecksumTable(ctx, table, manifest.KeyColumns, manifest.WhereClause)
+	if err != nil {
+		return fmt.Errorf("failed to recompute source checksum: %v", err)
+	}
+
+	if checksum != manifest.SourceChecksum {
+		return fmt.Errorf("checksum mismatch for %s: extraction recorded %s, source is now %s - the source changed or the extraction was incomplete", table, manifest.SourceChecksum, checksum)
+	}
+
+	totalRows, err := db.GetTotalRows(ctx, table)
+	if err != nil {
+		return fmt.Errorf("failed to get total rows: %v", err)
+	}
+	for _, part := range manifest.Parts {
+		if len(part.Batches) > 0 {
+			if err := verify.VerifyExtract(part.Path, manifest, totalRows); err != nil {
+				return fmt.Errorf("per-batch verification failed for %s: %v", part.Path, err)
+			}
+			continue
+		}
+		// A shard written by extractor.Coordinator (-parallelism > 1)
+		// only records one running checksum for its whole output file,
+		// not a per-batch breakdown.
+		if part.Checksum != "" {
+			if err := verify.VerifyShardFile(part, manifest.Format); err != nil {
+				return fmt.Errorf("shard verification failed for %s: %v", part.Path, err)
+			}
+		}
+	}
+
+	log.Printf("Verification passed: %s matches the manifest recorded at %s (%d part(s))", table, manifest.CreatedAt.Format("2006-01-02T15:04:05Z07:00"), len(manifest.Parts))
+	return nil
+}
+
+// runRegisterGlue registers table's schema and outputFile's location with
+// a Catalog after a successful extraction, so the data is immediately
+// queryable through Athena/Spark. It uses a GlueCatalog unless localDir is
+// set, in which case it writes a local _schema.json sidecar instead (for
+// offline runs). Column types come from db's SchemaDescriber
+// implementation when available, falling back to GetColumns (names only,
+// reported as "string") for drivers that don't implement it.
+func runRegisterGlue(ctx context.Context, db database.Database, table, outputFile, format, glueDB, localDir, partitions string) error {
+	var cols []database.Column
+	if describer, ok := db.(database.SchemaDescriber); ok {
+		schema, err := describer.GetTableSchema(ctx, table)
+		if err != nil {
+			return fmt.Errorf("failed to get table schema: %v", err)
+		}
+		cols = schema
+	} else {
+		names, err := db.GetColumns(ctx, table)
+		if err != nil {
+			return fmt.Errorf("failed to get columns: %v", err)
+		}
+		cols = make([]database.Column, len(names))
+		for i, name := range names {
+			cols[i] = database.Column{Name: name, Type: "string"}
+		}
+	}
+
+	var partitionCols []database.Column
+	if partitions != "" {
+		for _, name := range strings.Split(partitions, ",") {
+			partitionCols = append(partitionCols, database.Column{Name: name, Type: "string"})
+		}
+	}
+
+	kind := "glue"
+	if localDir != "" {
+		kind = "local"
+	}
+	cat, err := catalog.New(kind, localDir)
+	if err != nil {
+		return fmt.Errorf("failed to create catalog: %v", err)
+	}
+
+	if err := cat.EnsureDatabase(ctx, glueDB); err != nil {
+		return fmt.Errorf("failed to ensure catalog database %s: %v", glueDB, err)
+	}
+
+	location := outputFile
+	if sink.IsRemote(outputFile) {
+		if idx := strings.LastIndex(outputFile, "/"); idx != -1 {
+			location = outputFile[:idx] + "/"
+		}
+	}
+
+	if err := cat.EnsureTable(ctx, glueDB, table, cols, location, format, partitionCols); err != nil {
+		return fmt.Errorf("failed to register table %s.%s: %v", glueDB, table, err)
+	}
+
+	log.Printf("Registered %s.%s with the %s catalog at %s", glueDB, table, kind, location)
+	return nil
+}
+
+// runCDC extracts only the rows changed since the last -cdc call for
+// table, via db's CDCCapable implementation, logging how many it wrote.
+// It returns an error if db doesn't implement CDCCapable, since only
+// PostgresDB (logical replication) and MSSQLDB (SQL Server CDC) do.
+func runCDC(ctx context.Context, db database.Database, table, output, source string) error {
+	cdcDB, ok := db.(database.CDCCapable)
+	if !ok {
+		return fmt.Errorf("-cdc is not supported for this database type")
+	}
+
+	n, err := cdcDB.ExtractCDC(ctx, table, output, source)
+	if err != nil {
+		return fmt.Errorf("failed to extract changes: %v", err)
+	}
+
+	log.Printf("CDC extraction wrote %d changed row(s) for %s", n, table)
+	return nil
+}
+
+// runIncremental extracts rows newer than the previously checkpointed
+// high watermark for table via database.IncrementalExtractor, logging how
+// many it wrote. Unlike runCDC it works for every database type, since it
+// only relies on GetColumns/ExtractBatch rather than a native change feed.
+func runIncremental(ctx context.Context, db database.Database, checkpointStoreURI, jobID, table, output, watermarkColumn, since string, batchSize int) error {
+	if jobID == "" {
+		jobID = table
+	}
+	store, err := checkpoint.NewStore(checkpointStoreURI)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %v", err)
+	}
+	defer store.Close()
+
+	inc := database.NewIncrementalExtractor(db, store, jobID, table, watermarkColumn, int64(batchSize))
+	n, err := inc.Extract(ctx, output, since)
+	if err != nil {
+		return fmt.Errorf("failed to extract changed rows: %v", err)
+	}
+
+	log.Printf("Incremental extraction wrote %d changed row(s) for %s", n, table)
+	return nil
+}
+
+// runParallel extracts table with a extractor.Coordinator instead of
+// db.ExtractData, splitting it into -parallelism shards by -shard-strategy
+// and writing each to its own "<output>.shardN.<format>" file. id defaults
+// to table so a single process run needs no extra flag, but cooperating
+// processes must pass the same -job-id explicitly to share shard state.
+func runParallel(ctx context.Context, db database.Database, stateManager state.Manager, dbType, table, id, output, format, keyColumns, whereClause, checkpointStoreURI string, parallelism, batchSize int, strategy extractor.Strategy) error {
+	if id == "" {
+		id = table
+	}
+	coordinator := extractor.New(db, stateManager, dbType, id, parallelism, strategy)
+	if checkpointStoreURI != "" {
+		store, err := checkpoint.NewStore(checkpointStoreURI)
+		if err != nil {
+			return fmt.Errorf("failed to open checkpoint store: %v", err)
+		}
+		defer store.Close()
+		coordinator.SetCheckpointStore(store)
+	}
+	return coordinator.Extract(ctx, table, output, format, keyColumns, whereClause, batchSize)
+}
+
+// runDaemon handles the `sqlextract daemon` subcommand: instead of
+// exiting after one extraction, it keeps the process running and fires
+// an extraction of -table on -schedule (a robfig/cron/v3 expression,
+// including "@every <duration>") until interrupted. Like runMigrate, it
+// uses its own flag.FlagSet since the subcommand's flags don't overlap
+// cleanly with the top-level one-shot flags (-output becomes a
+// text/template, and there's no -resume-mode/-verify/-cdc/-parallelism).
+func runDaemon(args []string) {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	dDBType := fs.String("type", "", "Database type (postgres, mssql, bigquery, snowflake, databricks, duckdb)")
+	dHost := fs.String("host", "", "Database host")
+	dPort := fs.Int("port", 0, "Database port")
+	dUser := fs.String("user", "", "Database user")
+	dPassword := fs.String("password", "", "Database password")
+	dDBName := fs.String("database", "", "Database name")
+	dSchema := fs.String("schema", "", "Database schema (optional)")
+	dProjectID := fs.String("project", "", "Google Cloud project ID (required for BigQuery)")
+	dAccount := fs.String("account", "", "Snowflake account identifier")
+	dWarehouse := fs.String("warehouse", "", "Snowflake warehouse name")
+	dRole := fs.String("role", "", "Snowflake role name")
+	dTable := fs.String("table", "", "Table name to extract")
+	dOutput := fs.String("output", "", "Output file path template (Go text/template referencing .JobID and .Timestamp), e.g. \"out/{{.Timestamp}}-{{.JobID}}.csv\"")
+	dFormat := fs.String("format", "csv", "Output format (csv or parquet)")
+	dBatchSize := fs.Int("batch-size", 1000, "Number of rows to extract in each batch")
+	dKeyColumns := fs.String("keys", "", "Comma-separated list of key columns for pagination")
+	dWhereClause := fs.String("where", "", "SQL WHERE clause for filtering data")
+	dSchedule := fs.String("schedule", "", "Cron schedule (robfig/cron/v3 syntax), e.g. \"@every 15m\" or \"0 */4 * * *\"")
+	dOnOverlap := fs.String("on-overlap", "skip", "What to do when a fire starts while the previous one is still running: skip, queue, or cancel-previous")
+	dNamespace := fs.String("namespace", "default", "Kubernetes namespace for state management")
+	dStateType := fs.String("state-type", "memory", "State management type (memory, kubernetes, postgres, redis, bolt, or etcd)")
+	dStateDSN := fs.String("state-dsn", "", "Postgres connection string (used with -state-type=postgres)")
+	dRedisAddr := fs.String("redis-addr", "localhost:6379", "Redis address (used with -state-type=redis)")
+	dRedisPassword := fs.String("redis-password", "", "Redis password (used with -state-type=redis)")
+	dRedisDB := fs.Int("redis-db", 0, "Redis logical database number (used with -state-type=redis)")
+	dBoltPath := fs.String("bolt-path", "sqlextract-state.db", "BoltDB file path (used with -state-type=bolt)")
+	dEtcdEndpoints := fs.String("etcd-endpoints", "localhost:2379", "Comma-separated etcd endpoints (used with -state-type=etcd)")
+	fs.Parse(args)
+
+	if *dSchedule == "" {
+		log.Fatal("-schedule is required. Use -help for more information.")
+	}
+	var policy daemon.OverlapPolicy
+	switch *dOnOverlap {
+	case "skip", "queue", "cancel-previous":
+		policy = daemon.OverlapPolicy(*dOnOverlap)
+	default:
+		log.Fatalf("Unsupported -on-overlap: %s. Use -help for more information.", *dOnOverlap)
+	}
+
+	var stateManager state.Manager
+	var err error
+	switch *dStateType {
+	case "kubernetes":
+		stateManager, err = state.NewKubernetesManager(*dNamespace, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create Kubernetes state manager: %v", err)
+		}
+	case "postgres":
+		stateManager, err = state.NewPostgresManager(*dStateDSN, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create Postgres state manager: %v", err)
+		}
+	case "redis":
+		stateManager = state.NewRedisManager(*dRedisAddr, *dRedisPassword, *dRedisDB, processOwnerID())
+	case "bolt":
+		stateManager, err = state.NewBoltManager(*dBoltPath, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create BoltDB state manager: %v", err)
+		}
+	case "etcd":
+		stateManager, err = state.NewEtcdManager(state.ParseEndpoints(*dEtcdEndpoints), *dNamespace, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create etcd state manager: %v", err)
+		}
+	default:
+		stateManager = state.NewMemoryManager()
+	}
 
-	// Handle interrupt signals
+	cfg := &config.Config{
+		Type:      *dDBType,
+		Host:      *dHost,
+		Port:      *dPort,
+		User:      *dUser,
+		Password:  *dPassword,
+		Database:  *dDBName,
+		Schema:    *dSchema,
+		ProjectID: *dProjectID,
+		Account:   *dAccount,
+		Warehouse: *dWarehouse,
+		Role:      *dRole,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		log.Println("daemon: received shutdown signal")
+		cancel()
+	}()
+
+	db, err := database.NewDatabase(*dDBType, cfg, stateManager)
+	if err != nil {
+		log.Fatalf("Failed to create database instance: %v", err)
+	}
+	if err := db.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close(ctx)
+
+	// cancel-previous cancels runCtx for a fire still in progress when the
+	// next one starts; ExtractData now takes ctx throughout, so a canceled
+	// fire stops at its next batch instead of running to completion.
+	d := daemon.New(stateManager, *dTable, *dSchedule, policy, func(runCtx context.Context, run daemon.Run) error {
+		outputFile, err := run.OutputPath(*dOutput)
+		if err != nil {
+			return err
+		}
+		return db.ExtractData(runCtx, *dTable, outputFile, *dFormat, *dBatchSize, *dKeyColumns, *dWhereClause, "auto")
+	})
+
+	log.Printf("daemon: extracting %s on schedule %q (overlap policy: %s)", *dTable, *dSchedule, policy)
+	if err := d.Run(ctx); err != nil {
+		log.Fatalf("daemon failed: %v", err)
+	}
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+		runDaemon(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ingest" {
+		runIngest(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "checkpoint" {
+		runCheckpoint(os.Args[2:])
+		return
+	}
+
+	initFlags()
+
+	// A root context canceled on SIGINT/SIGTERM, so a ctx-aware step
+	// (ExtractBatch, Exec, ExtractArrow, and now every Database method)
+	// stops at its next opportunity instead of running to completion.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Initialize state manager
 	var stateManager state.Manager
 	var err error
-	if *stateType == "kubernetes" {
-		stateManager, err = state.NewKubernetesManager(*namespace)
+	switch *stateType {
+	case "kubernetes":
+		stateManager, err = state.NewKubernetesManager(*namespace, processOwnerID())
 		if err != nil {
 			log.Fatalf("Failed to create Kubernetes state manager: %v", err)
 		}
-	} else {
+	case "postgres":
+		stateManager, err = state.NewPostgresManager(*stateDSN, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create Postgres state manager: %v", err)
+		}
+	case "redis":
+		stateManager = state.NewRedisManager(*redisAddr, *redisPassword, *redisDB, processOwnerID())
+	case "bolt":
+		stateManager, err = state.NewBoltManager(*boltPath, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create BoltDB state manager: %v", err)
+		}
+	case "etcd":
+		stateManager, err = state.NewEtcdManager(state.ParseEndpoints(*etcdEndpoints), *namespace, processOwnerID())
+		if err != nil {
+			log.Fatalf("Failed to create etcd state manager: %v", err)
+		}
+	default:
 		stateManager = state.NewMemoryManager()
 	}
 
@@ -165,10 +974,41 @@ func main() {
 		Account:   *sfAccount,
 		Warehouse: *sfWarehouse,
 		Role:      *sfRole,
+		Async:     *sfAsync,
 		// Databricks specific
 		Workspace: *dbWorkspace,
 		Token:     *dbToken,
 		Catalog:   *dbCatalog,
+		// Bulk unload
+		Unload:      *unload,
+		UnloadStage: *unloadStage,
+		GCSBucket:   *gcsBucket,
+		// Per-call timeout
+		BatchTimeoutSeconds: *batchTimeout,
+	}
+
+	// Start the /metrics endpoint, if requested, so a long-running
+	// invocation can be scraped while it works.
+	if *metricsAddr != "" {
+		if err := metrics.Serve(*metricsAddr); err != nil {
+			log.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}
+
+	// pushMetrics sends the process's final metric values to the
+	// Pushgateway on exit, for one-shot cron invocations that no scraper
+	// ever reaches. It's a no-op unless -pushgateway-url is set.
+	pushMetrics := func(success bool) {
+		if *pushgatewayURL == "" {
+			return
+		}
+		if success {
+			if err := metrics.Push(*pushgatewayURL, *pushgatewayJob); err != nil {
+				log.Printf("Failed to push metrics: %v", err)
+			}
+		} else if err := metrics.PushPartial(*pushgatewayURL, *pushgatewayJob); err != nil {
+			log.Printf("Failed to push metrics: %v", err)
+		}
 	}
 
 	// Create database instance
@@ -178,15 +1018,92 @@ func main() {
 	}
 
 	// Connect to database
-	if err := db.Connect(); err != nil {
+	if err := db.Connect(ctx); err != nil {
+		metrics.RecordError(*dbType, *table)
+		pushMetrics(false)
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
+	defer db.Close(ctx)
+
+	// Auto-discover a primary key for keyset pagination when the user
+	// hasn't passed -keys, so plain extractions get O(N) paging instead
+	// of OFFSET's O(N^2) without requiring every caller to know their
+	// table's key up front. -resume-mode=keyset and -parallelism > 1
+	// already require -keys explicitly (see initFlags) and are left
+	// alone here.
+	effectiveKeyColumns := *keyColumns
+	if effectiveKeyColumns == "" {
+		if pkd, ok := db.(database.PrimaryKeyDiscoverer); ok {
+			if pk, err := pkd.GetPrimaryKey(ctx, *table); err != nil {
+				log.Printf("Failed to auto-discover primary key for %s, falling back to offset pagination: %v", *table, err)
+			} else if pk != "" {
+				log.Printf("Auto-discovered primary key %q for %s, using keyset pagination", pk, *table)
+				effectiveKeyColumns = pk
+			}
+		}
+	}
+
+	if *verifyFlag {
+		if err := runVerify(ctx, db, stateManager, *table); err != nil {
+			log.Fatalf("Verification failed: %v", err)
+		}
+		return
+	}
+
+	if *cdc {
+		if err := runCDC(ctx, db, *table, *output, *cdcSource); err != nil {
+			metrics.RecordError(*dbType, *table)
+			pushMetrics(false)
+			log.Fatalf("CDC extraction failed: %v", err)
+		}
+		metrics.RecordSuccess(*dbType, *table)
+		pushMetrics(true)
+		return
+	}
+
+	if *watermarkColumn != "" {
+		if err := runIncremental(ctx, db, *checkpointStore, *jobID, *table, *output, *watermarkColumn, *watermarkSince, *batchSize); err != nil {
+			metrics.RecordError(*dbType, *table)
+			pushMetrics(false)
+			log.Fatalf("Incremental extraction failed: %v", err)
+		}
+		metrics.RecordSuccess(*dbType, *table)
+		pushMetrics(true)
+		return
+	}
+
+	if *parallelism > 1 {
+		done := metrics.JobStarted(*dbType, *table)
+		err := runParallel(ctx, db, stateManager, *dbType, *table, *jobID, *output, *outputFormat, effectiveKeyColumns, *whereClause, *checkpointStore, *parallelism, *batchSize, extractor.Strategy(*shardStrategy))
+		done()
+		if err != nil {
+			metrics.RecordError(*dbType, *table)
+			pushMetrics(false)
+			log.Fatalf("Parallel extraction failed: %v", err)
+		}
+		metrics.RecordSuccess(*dbType, *table)
+		pushMetrics(true)
+		log.Println("Extraction completed successfully")
+		return
+	}
 
 	// Extract data
-	if err := db.ExtractData(*table, *output, *outputFormat, *batchSize, *keyColumns, *whereClause); err != nil {
+	done := metrics.JobStarted(*dbType, *table)
+	err = db.ExtractData(ctx, *table, *output, *outputFormat, *batchSize, effectiveKeyColumns, *whereClause, *resumeMode)
+	done()
+	if err != nil {
+		metrics.RecordError(*dbType, *table)
+		pushMetrics(false)
 		log.Fatalf("Failed to extract data: %v", err)
 	}
+	metrics.RecordSuccess(*dbType, *table)
+	pushMetrics(true)
+
+	if *registerGlue {
+		if err := runRegisterGlue(ctx, db, *table, *output, *outputFormat, *glueDatabase, *glueLocalDir, *gluePartition); err != nil {
+			log.Fatalf("Failed to register catalog entry: %v", err)
+		}
+	}
 
 	log.Println("Extraction completed successfully")
 }
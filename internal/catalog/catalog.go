@@ -0,0 +1,51 @@
+// Package catalog registers the schema of an extracted table with an
+// external table catalog, so Parquet landed in S3 by ExtractData can
+// immediately be queried through Athena/Spark without an operator
+// hand-writing a CREATE TABLE. GlueCatalog does this against AWS Glue
+// Data Catalog; LocalFSCatalog writes an equivalent sidecar file next to
+// the output, so the same -register-glue flow works offline.
+package catalog
+
+import (
+	"context"
+
+	"github.com/gerhard-ee/sqlextract/internal/database"
+)
+
+// Catalog registers and keeps up to date the catalog entry for one
+// extracted table.
+type Catalog interface {
+	// EnsureDatabase creates db if it doesn't already exist.
+	EnsureDatabase(ctx context.Context, db string) error
+	// EnsureTable creates or updates db.table's schema, location, file
+	// format, and partition columns, idempotently: calling it again with
+	// the same arguments leaves an already-matching catalog entry
+	// unchanged.
+	EnsureTable(ctx context.Context, db, table string, cols []database.Column, location, format string, partitions []database.Column) error
+	// UpdatePartitions registers newly-written partition values for
+	// db.table - one []string per partition, in partitions column order -
+	// for formats (e.g. Hive-style partitioned Parquet) where the catalog
+	// tracks partitions explicitly instead of discovering them by listing
+	// location.
+	UpdatePartitions(ctx context.Context, db, table string, partitionValues [][]string) error
+}
+
+// New returns the Catalog implementation for kind: "glue" for
+// GlueCatalog, or "local" for LocalFSCatalog. baseDir is only used by
+// "local".
+func New(kind, baseDir string) (Catalog, error) {
+	switch kind {
+	case "glue":
+		return NewGlueCatalog(context.Background())
+	case "local":
+		return NewLocalFSCatalog(baseDir), nil
+	default:
+		return nil, errUnsupportedKind(kind)
+	}
+}
+
+type errUnsupportedKind string
+
+func (k errUnsupportedKind) Error() string {
+	return "unsupported catalog kind: " + string(k)
+}
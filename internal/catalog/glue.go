@@ -0,0 +1,182 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/glue/types"
+
+	"github.com/gerhard-ee/sqlextract/internal/database"
+)
+
+// glueClient is the subset of *glue.Client GlueCatalog calls, so tests can
+// substitute a fake without standing up real AWS credentials.
+type glueClient interface {
+	GetDatabase(ctx context.Context, in *glue.GetDatabaseInput, opts ...func(*glue.Options)) (*glue.GetDatabaseOutput, error)
+	CreateDatabase(ctx context.Context, in *glue.CreateDatabaseInput, opts ...func(*glue.Options)) (*glue.CreateDatabaseOutput, error)
+	GetTable(ctx context.Context, in *glue.GetTableInput, opts ...func(*glue.Options)) (*glue.GetTableOutput, error)
+	CreateTable(ctx context.Context, in *glue.CreateTableInput, opts ...func(*glue.Options)) (*glue.CreateTableOutput, error)
+	UpdateTable(ctx context.Context, in *glue.UpdateTableInput, opts ...func(*glue.Options)) (*glue.UpdateTableOutput, error)
+	BatchCreatePartition(ctx context.Context, in *glue.BatchCreatePartitionInput, opts ...func(*glue.Options)) (*glue.BatchCreatePartitionOutput, error)
+}
+
+// GlueCatalog registers extracted tables with AWS Glue Data Catalog, so
+// they're immediately queryable through Athena/Spark once their Parquet
+// files land in S3.
+type GlueCatalog struct {
+	client glueClient
+}
+
+// NewGlueCatalog builds a GlueCatalog using the ambient AWS credentials
+// (environment, shared config, or instance role), the same resolution
+// sink.newS3Sink relies on for S3 uploads.
+func NewGlueCatalog(ctx context.Context) (*GlueCatalog, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+	return &GlueCatalog{client: glue.NewFromConfig(cfg)}, nil
+}
+
+func (g *GlueCatalog) EnsureDatabase(ctx context.Context, db string) error {
+	_, err := g.client.GetDatabase(ctx, &glue.GetDatabaseInput{Name: aws.String(db)})
+	if err == nil {
+		return nil
+	}
+	var notFound *types.EntityNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to look up Glue database %s: %v", db, err)
+	}
+
+	_, err = g.client.CreateDatabase(ctx, &glue.CreateDatabaseInput{
+		DatabaseInput: &types.DatabaseInput{Name: aws.String(db)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create Glue database %s: %v", db, err)
+	}
+	return nil
+}
+
+func (g *GlueCatalog) EnsureTable(ctx context.Context, db, table string, cols []database.Column, location, format string, partitions []database.Column) error {
+	input := glueTableInput(table, cols, location, format, partitions)
+
+	_, err := g.client.GetTable(ctx, &glue.GetTableInput{DatabaseName: aws.String(db), Name: aws.String(table)})
+	if err == nil {
+		_, err = g.client.UpdateTable(ctx, &glue.UpdateTableInput{DatabaseName: aws.String(db), TableInput: input})
+		if err != nil {
+			return fmt.Errorf("failed to update Glue table %s.%s: %v", db, table, err)
+		}
+		return nil
+	}
+
+	var notFound *types.EntityNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to look up Glue table %s.%s: %v", db, table, err)
+	}
+
+	_, err = g.client.CreateTable(ctx, &glue.CreateTableInput{DatabaseName: aws.String(db), TableInput: input})
+	if err != nil {
+		return fmt.Errorf("failed to create Glue table %s.%s: %v", db, table, err)
+	}
+	return nil
+}
+
+func (g *GlueCatalog) UpdatePartitions(ctx context.Context, db, table string, partitionValues [][]string) error {
+	if len(partitionValues) == 0 {
+		return nil
+	}
+
+	inputs := make([]types.PartitionInput, len(partitionValues))
+	for i, values := range partitionValues {
+		inputs[i] = types.PartitionInput{Values: values}
+	}
+
+	out, err := g.client.BatchCreatePartition(ctx, &glue.BatchCreatePartitionInput{
+		DatabaseName:       aws.String(db),
+		TableName:          aws.String(table),
+		PartitionInputList: inputs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register partitions for %s.%s: %v", db, table, err)
+	}
+	if len(out.Errors) > 0 {
+		return fmt.Errorf("failed to register %d of %d partitions for %s.%s: %s", len(out.Errors), len(partitionValues), db, table, aws.ToString(out.Errors[0].ErrorDetail.ErrorMessage))
+	}
+	return nil
+}
+
+func glueTableInput(table string, cols []database.Column, location, format string, partitions []database.Column) *types.TableInput {
+	serde, inputFormat, outputFormat := glueFormat(format)
+
+	return &types.TableInput{
+		Name:          aws.String(table),
+		TableType:     aws.String("EXTERNAL_TABLE"),
+		PartitionKeys: glueColumns(partitions),
+		StorageDescriptor: &types.StorageDescriptor{
+			Columns:      glueColumns(cols),
+			Location:     aws.String(location),
+			InputFormat:  aws.String(inputFormat),
+			OutputFormat: aws.String(outputFormat),
+			SerdeInfo:    &types.SerDeInfo{SerializationLibrary: aws.String(serde)},
+		},
+	}
+}
+
+func glueColumns(cols []database.Column) []types.Column {
+	out := make([]types.Column, len(cols))
+	for i, col := range cols {
+		out[i] = types.Column{Name: aws.String(col.Name), Type: aws.String(glueType(col.Type))}
+	}
+	return out
+}
+
+// glueFormat returns the SerDe and input/output format classes Athena
+// needs to read format ("csv" or "parquet").
+func glueFormat(format string) (serde, inputFormat, outputFormat string) {
+	switch strings.ToLower(format) {
+	case "parquet":
+		return "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe",
+			"org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat",
+			"org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat"
+	default:
+		return "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe",
+			"org.apache.hadoop.mapred.TextInputFormat",
+			"org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat"
+	}
+}
+
+// glueType maps a source column's database-reported type to the Hive type
+// string Glue/Athena expects, defaulting to "string" for anything not
+// recognized so an unfamiliar source type never blocks registration.
+func glueType(sourceType string) string {
+	t := strings.ToLower(sourceType)
+	switch {
+	case strings.Contains(t, "bigint") || strings.Contains(t, "int8"):
+		return "bigint"
+	case strings.Contains(t, "smallint") || strings.Contains(t, "int2"):
+		return "smallint"
+	case strings.Contains(t, "int"):
+		return "int"
+	case strings.Contains(t, "double") || strings.Contains(t, "float8"):
+		return "double"
+	case strings.Contains(t, "real") || strings.Contains(t, "float4") || strings.Contains(t, "float"):
+		return "float"
+	case strings.Contains(t, "numeric") || strings.Contains(t, "decimal"):
+		return "decimal(38,9)"
+	case strings.Contains(t, "bool"):
+		return "boolean"
+	case strings.Contains(t, "timestamp"):
+		return "timestamp"
+	case strings.Contains(t, "date"):
+		return "date"
+	case strings.Contains(t, "binary") || strings.Contains(t, "blob") || strings.Contains(t, "bytea"):
+		return "binary"
+	default:
+		return "string"
+	}
+}
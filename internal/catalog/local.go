@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gerhard-ee/sqlextract/internal/database"
+)
+
+// localSchema is the JSON shape LocalFSCatalog writes to <table>_schema.json,
+// mirroring the fields GlueCatalog.EnsureTable registers.
+type localSchema struct {
+	Database   string            `json:"database"`
+	Table      string            `json:"table"`
+	Columns    []database.Column `json:"columns"`
+	Location   string            `json:"location"`
+	Format     string            `json:"format"`
+	Partitions []database.Column `json:"partitions,omitempty"`
+}
+
+// LocalFSCatalog is a Catalog that writes a `<table>_schema.json` sidecar
+// file next to the extracted output instead of calling out to AWS Glue, so
+// the `-register-glue` flow also works against a local filesystem, e.g. in
+// tests or offline runs.
+type LocalFSCatalog struct {
+	baseDir string
+}
+
+// NewLocalFSCatalog returns a LocalFSCatalog that writes its sidecar files
+// under baseDir.
+func NewLocalFSCatalog(baseDir string) *LocalFSCatalog {
+	return &LocalFSCatalog{baseDir: baseDir}
+}
+
+// EnsureDatabase is a no-op: LocalFSCatalog has no database-level concept,
+// only per-table sidecar files.
+func (c *LocalFSCatalog) EnsureDatabase(ctx context.Context, db string) error {
+	return nil
+}
+
+func (c *LocalFSCatalog) EnsureTable(ctx context.Context, db, table string, cols []database.Column, location, format string, partitions []database.Column) error {
+	schema := localSchema{
+		Database:   db,
+		Table:      table,
+		Columns:    cols,
+		Location:   location,
+		Format:     format,
+		Partitions: partitions,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema for %s.%s: %v", db, table, err)
+	}
+
+	if err := os.MkdirAll(c.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create catalog directory %s: %v", c.baseDir, err)
+	}
+
+	path := filepath.Join(c.baseDir, fmt.Sprintf("%s_schema.json", table))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schema sidecar %s: %v", path, err)
+	}
+	return nil
+}
+
+// UpdatePartitions is a no-op: the sidecar file written by EnsureTable
+// already records the partition columns, and a local filesystem has no
+// separate partition registry to update as new partition values show up.
+func (c *LocalFSCatalog) UpdatePartitions(ctx context.Context, db, table string, partitionValues [][]string) error {
+	return nil
+}
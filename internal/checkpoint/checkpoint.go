@@ -0,0 +1,102 @@
+// Package checkpoint tracks the fine-grained, per-chunk progress of an
+// extraction - one level below state.Manager's per-job/per-shard status,
+// modeled on TiDB Lightning's checkpoint database. Where state.Manager
+// answers "is this job's lease still held, and where did it last leave
+// off", a checkpoint.Store answers "exactly which chunks of this table
+// are safely written, which are mid-flight, and which failed" - so an
+// operator can inspect, wipe, and resume a single bad chunk (via the
+// checkpoint-dump/-restore/-error-destroy operations in ctl.go) instead
+// of restarting the whole extraction from row 0.
+package checkpoint
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Status is a chunk's position in its write lifecycle, named after TiDB
+// Lightning's checkpoint states.
+type Status string
+
+const (
+	// StatusLoading means a chunk has been claimed but its rows haven't
+	// been read from the source yet.
+	StatusLoading Status = "loading"
+	// StatusLoaded means a chunk's rows have been read into memory but
+	// none have been written to the output yet.
+	StatusLoaded Status = "loaded"
+	// StatusWritten means at least one batch of a chunk's rows has been
+	// written to the output, but the chunk isn't complete yet.
+	StatusWritten Status = "written"
+	// StatusFinished means every row of a chunk has been written and
+	// the chunk needs no further work.
+	StatusFinished Status = "finished"
+	// StatusFailed means extraction of a chunk errored out; LastError
+	// holds the reason and the chunk's output should be treated as
+	// unreliable until checkpoint-error-destroy wipes it and it's retried.
+	StatusFailed Status = "failed"
+)
+
+// Chunk is the checkpoint record for one chunk of one table within one
+// job - a shard, range partition, or CDC batch, depending on which
+// caller is writing it.
+type Chunk struct {
+	JobID      string
+	Table      string
+	ChunkID    string
+	Status     Status
+	RowCount   int64
+	ByteOffset int64
+	// MinKey and MaxKey bound the chunk's key range, in key-column
+	// order, the same cursor tuple shape as state.State.LastKey.
+	MinKey    []interface{}
+	MaxKey    []interface{}
+	Checksum  string
+	LastError string
+	UpdatedAt time.Time
+}
+
+// id returns the key a Store indexes c under.
+func (c *Chunk) id() string {
+	return chunkID(c.JobID, c.Table, c.ChunkID)
+}
+
+func chunkID(jobID, table, chunkID string) string {
+	return strings.Join([]string{jobID, table, chunkID}, "/")
+}
+
+// Store persists Chunk checkpoints. Implementations must be safe for
+// concurrent use by a process's worker pool.
+type Store interface {
+	// Save upserts a chunk's checkpoint record, keyed by
+	// (c.JobID, c.Table, c.ChunkID).
+	Save(ctx context.Context, c *Chunk) error
+	// Get returns the checkpoint for (jobID, table, chunkID), or nil if
+	// none has been saved.
+	Get(ctx context.Context, jobID, table, chunkID string) (*Chunk, error)
+	// List returns every chunk checkpointed for jobID and table, in no
+	// particular order.
+	List(ctx context.Context, jobID, table string) ([]*Chunk, error)
+	// Delete removes a chunk's checkpoint record, e.g. after
+	// checkpoint-error-destroy has wiped its partial output.
+	Delete(ctx context.Context, jobID, table, chunkID string) error
+	// Close releases any resources the Store holds open.
+	Close() error
+}
+
+// NewStore selects a Store implementation by uri's scheme: "sqlite://"
+// for a local SQLite database (via modernc.org/sqlite), "s3://"/"gs://"
+// for remote object storage, and anything else (including a bare path)
+// for a directory of local JSON files - mirroring internal/sink.New's
+// URI dispatch.
+func NewStore(uri string) (Store, error) {
+	switch {
+	case strings.HasPrefix(uri, "sqlite://"):
+		return newSQLiteStore(strings.TrimPrefix(uri, "sqlite://"))
+	case strings.HasPrefix(uri, "s3://"), strings.HasPrefix(uri, "gs://"):
+		return newRemoteStore(uri)
+	default:
+		return newFileStore(uri)
+	}
+}
@@ -0,0 +1,64 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Dump returns every chunk checkpointed for jobID and table, sorted by
+// ChunkID, mirroring `tidb-lightning-ctl --checkpoint-dump` - a
+// human-readable snapshot of exactly where an extraction stands, chunk
+// by chunk, without having to reason about state.Manager's single
+// per-job/per-shard status.
+func Dump(ctx context.Context, store Store, jobID, table string) ([]*Chunk, error) {
+	chunks, err := store.List(ctx, jobID, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump checkpoints: %v", err)
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].ChunkID < chunks[j].ChunkID })
+	return chunks, nil
+}
+
+// Restore re-saves chunks into store, used to load a checkpoint snapshot
+// (e.g. one produced by Dump and hand-edited, or copied from another
+// store) back in place, mirroring `tidb-lightning-ctl --checkpoint-restore`.
+func Restore(ctx context.Context, store Store, chunks []*Chunk) error {
+	for _, c := range chunks {
+		if err := store.Save(ctx, c); err != nil {
+			return fmt.Errorf("failed to restore checkpoint for chunk %s: %v", c.ChunkID, err)
+		}
+	}
+	return nil
+}
+
+// ErrorDestroy wipes outputFile (the partial output a failed chunk left
+// behind, if any) and deletes the chunk's checkpoint record, so a retry
+// starts that chunk clean instead of appending to or half-overwriting
+// unreliable bytes - mirroring `tidb-lightning-ctl --checkpoint-error-destroy`.
+// It requires the chunk to be checkpointed as StatusFailed, refusing to
+// destroy output for a chunk that's still in flight or already finished.
+func ErrorDestroy(ctx context.Context, store Store, jobID, table, chunkID, outputFile string) error {
+	c, err := store.Get(ctx, jobID, table, chunkID)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint for chunk %s: %v", chunkID, err)
+	}
+	if c == nil {
+		return fmt.Errorf("no checkpoint found for chunk %s/%s/%s", jobID, table, chunkID)
+	}
+	if c.Status != StatusFailed {
+		return fmt.Errorf("refusing to destroy chunk %s: status is %q, not %q", chunkID, c.Status, StatusFailed)
+	}
+
+	if outputFile != "" {
+		if err := os.Remove(outputFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove partial output %s: %v", outputFile, err)
+		}
+	}
+
+	if err := store.Delete(ctx, jobID, table, chunkID); err != nil {
+		return fmt.Errorf("failed to delete checkpoint for chunk %s: %v", chunkID, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// fileStore implements Store as one JSON file per chunk under baseDir,
+// named "<jobID>_<table>_<chunkID>.checkpoint.json" with path separators
+// in any of those fields flattened so a chunkID like "shard0" can't
+// escape baseDir.
+type fileStore struct {
+	baseDir string
+	mu      sync.RWMutex
+}
+
+func newFileStore(baseDir string) (Store, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %v", err)
+	}
+	return &fileStore{baseDir: baseDir}, nil
+}
+
+func flattenPathComponent(s string) string {
+	return strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(s)
+}
+
+func (s *fileStore) path(jobID, table, chunkID string) string {
+	name := fmt.Sprintf("%s_%s_%s.checkpoint.json",
+		flattenPathComponent(jobID), flattenPathComponent(table), flattenPathComponent(chunkID))
+	return filepath.Join(s.baseDir, name)
+}
+
+func (s *fileStore) Save(ctx context.Context, c *Chunk) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunk checkpoint: %v", err)
+	}
+	if err := os.WriteFile(s.path(c.JobID, c.Table, c.ChunkID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write chunk checkpoint: %v", err)
+	}
+	return nil
+}
+
+func (s *fileStore) Get(ctx context.Context, jobID, table, chunkID string) (*Chunk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.path(jobID, table, chunkID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read chunk checkpoint: %v", err)
+	}
+
+	var c Chunk
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chunk checkpoint: %v", err)
+	}
+	return &c, nil
+}
+
+func (s *fileStore) List(ctx context.Context, jobID, table string) ([]*Chunk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint directory: %v", err)
+	}
+
+	var chunks []*Chunk
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".checkpoint.json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.baseDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var c Chunk
+		if err := json.Unmarshal(data, &c); err != nil {
+			continue
+		}
+		if c.JobID == jobID && c.Table == table {
+			chunks = append(chunks, &c)
+		}
+	}
+	return chunks, nil
+}
+
+func (s *fileStore) Delete(ctx context.Context, jobID, table, chunkID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(jobID, table, chunkID)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no checkpoint found for chunk %s/%s/%s", jobID, table, chunkID)
+		}
+		return fmt.Errorf("failed to delete chunk checkpoint: %v", err)
+	}
+	return nil
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
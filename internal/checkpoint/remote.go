@@ -0,0 +1,235 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// remoteStore implements Store against S3 or GCS, one JSON object per
+// chunk at "<prefix>/<jobID>/<table>/<chunkID>.json", for deployments
+// where multiple hosts need to see the same checkpoints without a
+// database of their own. Unlike internal/sink.Sink, which only appends
+// parts to an in-progress upload, a checkpoint needs whole-object
+// get/put/list/delete, so this talks to the S3/GCS SDKs directly rather
+// than going through sink.New.
+type remoteStore struct {
+	bucket string
+	prefix string
+
+	s3Client  *s3.Client
+	gcsClient *storage.Client
+}
+
+func splitRemoteURI(uri, scheme string) (bucket, prefix string, err error) {
+	trimmed := strings.TrimPrefix(uri, scheme)
+	parts := strings.SplitN(trimmed, "/", 2)
+	bucket = parts[0]
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid %s URI: %s", scheme, uri)
+	}
+	if len(parts) == 2 {
+		prefix = strings.TrimSuffix(parts[1], "/")
+	}
+	return bucket, prefix, nil
+}
+
+func newRemoteStore(uri string) (Store, error) {
+	ctx := context.Background()
+	switch {
+	case strings.HasPrefix(uri, "s3://"):
+		bucket, prefix, err := splitRemoteURI(uri, "s3://")
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %v", err)
+		}
+		return &remoteStore{bucket: bucket, prefix: prefix, s3Client: s3.NewFromConfig(cfg)}, nil
+	case strings.HasPrefix(uri, "gs://"):
+		bucket, prefix, err := splitRemoteURI(uri, "gs://")
+		if err != nil {
+			return nil, err
+		}
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %v", err)
+		}
+		return &remoteStore{bucket: bucket, prefix: prefix, gcsClient: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported remote checkpoint store URI: %s", uri)
+	}
+}
+
+func (s *remoteStore) objectKey(jobID, table, chunkID string) string {
+	key := fmt.Sprintf("%s/%s/%s.json", jobID, table, chunkID)
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	return key
+}
+
+func (s *remoteStore) Save(ctx context.Context, c *Chunk) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunk checkpoint: %v", err)
+	}
+	key := s.objectKey(c.JobID, c.Table, c.ChunkID)
+
+	if s.s3Client != nil {
+		_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(data),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save chunk checkpoint to S3: %v", err)
+		}
+		return nil
+	}
+
+	w := s.gcsClient.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to save chunk checkpoint to GCS: %v", err)
+	}
+	return w.Close()
+}
+
+func (s *remoteStore) Get(ctx context.Context, jobID, table, chunkID string) (*Chunk, error) {
+	key := s.objectKey(jobID, table, chunkID)
+
+	data, err := s.getObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var c Chunk
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chunk checkpoint: %v", err)
+	}
+	return &c, nil
+}
+
+// getObject returns key's contents, or (nil, nil) if it doesn't exist.
+func (s *remoteStore) getObject(ctx context.Context, key string) ([]byte, error) {
+	if s.s3Client != nil {
+		out, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+		if err != nil {
+			var nsk *types.NoSuchKey
+			if errors.As(err, &nsk) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get chunk checkpoint from S3: %v", err)
+		}
+		defer out.Body.Close()
+		data, err := io.ReadAll(out.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk checkpoint from S3: %v", err)
+		}
+		return data, nil
+	}
+
+	r, err := s.gcsClient.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get chunk checkpoint from GCS: %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk checkpoint from GCS: %v", err)
+	}
+	return data, nil
+}
+
+func (s *remoteStore) List(ctx context.Context, jobID, table string) ([]*Chunk, error) {
+	listPrefix := fmt.Sprintf("%s/%s/", jobID, table)
+	if s.prefix != "" {
+		listPrefix = s.prefix + "/" + listPrefix
+	}
+
+	var keys []string
+	if s.s3Client != nil {
+		paginator := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+			Bucket: aws.String(s.bucket),
+			Prefix: aws.String(listPrefix),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list chunk checkpoints in S3: %v", err)
+			}
+			for _, obj := range page.Contents {
+				keys = append(keys, aws.ToString(obj.Key))
+			}
+		}
+	} else {
+		it := s.gcsClient.Bucket(s.bucket).Objects(ctx, &storage.Query{Prefix: listPrefix})
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list chunk checkpoints in GCS: %v", err)
+			}
+			keys = append(keys, attrs.Name)
+		}
+	}
+
+	var chunks []*Chunk
+	for _, key := range keys {
+		data, err := s.getObject(ctx, key)
+		if err != nil || data == nil {
+			continue
+		}
+		var c Chunk
+		if err := json.Unmarshal(data, &c); err != nil {
+			continue
+		}
+		chunks = append(chunks, &c)
+	}
+	return chunks, nil
+}
+
+func (s *remoteStore) Delete(ctx context.Context, jobID, table, chunkID string) error {
+	key := s.objectKey(jobID, table, chunkID)
+
+	if s.s3Client != nil {
+		_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+		if err != nil {
+			return fmt.Errorf("failed to delete chunk checkpoint from S3: %v", err)
+		}
+		return nil
+	}
+
+	if err := s.gcsClient.Bucket(s.bucket).Object(key).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete chunk checkpoint from GCS: %v", err)
+	}
+	return nil
+}
+
+func (s *remoteStore) Close() error {
+	if s.gcsClient != nil {
+		return s.gcsClient.Close()
+	}
+	return nil
+}
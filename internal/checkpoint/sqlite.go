@@ -0,0 +1,128 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteStore implements Store against a local SQLite database via the
+// pure-Go modernc.org/sqlite driver, for single-host deployments that
+// want queryable, crash-safe checkpoints without standing up Postgres.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func newSQLiteStore(path string) (Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite checkpoint database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to sqlite checkpoint database: %v", err)
+	}
+
+	s := &sqliteStore{db: db}
+	if err := s.ensureSchema(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *sqliteStore) ensureSchema() error {
+	_, err := s.db.Exec(`
+CREATE TABLE IF NOT EXISTS sqlextract_checkpoints (
+	job_id TEXT NOT NULL,
+	table_name TEXT NOT NULL,
+	chunk_id TEXT NOT NULL,
+	status TEXT NOT NULL,
+	data TEXT NOT NULL,
+	PRIMARY KEY (job_id, table_name, chunk_id)
+);
+`)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint schema: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Save(ctx context.Context, c *Chunk) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunk checkpoint: %v", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO sqlextract_checkpoints (job_id, table_name, chunk_id, status, data)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (job_id, table_name, chunk_id) DO UPDATE SET status = excluded.status, data = excluded.data
+`, c.JobID, c.Table, c.ChunkID, string(c.Status), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save chunk checkpoint: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Get(ctx context.Context, jobID, table, chunkID string) (*Chunk, error) {
+	row := s.db.QueryRowContext(ctx, `
+SELECT data FROM sqlextract_checkpoints WHERE job_id = ? AND table_name = ? AND chunk_id = ?
+`, jobID, table, chunkID)
+
+	var data string
+	if err := row.Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get chunk checkpoint: %v", err)
+	}
+
+	var c Chunk
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chunk checkpoint: %v", err)
+	}
+	return &c, nil
+}
+
+func (s *sqliteStore) List(ctx context.Context, jobID, table string) ([]*Chunk, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT data FROM sqlextract_checkpoints WHERE job_id = ? AND table_name = ?
+`, jobID, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunk checkpoints: %v", err)
+	}
+	defer rows.Close()
+
+	var chunks []*Chunk
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk checkpoint: %v", err)
+		}
+		var c Chunk
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chunk checkpoint: %v", err)
+		}
+		chunks = append(chunks, &c)
+	}
+	return chunks, nil
+}
+
+func (s *sqliteStore) Delete(ctx context.Context, jobID, table, chunkID string) error {
+	res, err := s.db.ExecContext(ctx, `
+DELETE FROM sqlextract_checkpoints WHERE job_id = ? AND table_name = ? AND chunk_id = ?
+`, jobID, table, chunkID)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunk checkpoint: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no checkpoint found for chunk %s/%s/%s", jobID, table, chunkID)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
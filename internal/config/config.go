@@ -1,5 +1,19 @@
 package config
 
+import "time"
+
+// ConnectionPolicy controls connectWithRetry's attempts to open and ping
+// a driver's connection before giving up. The zero value (MaxAttempts ==
+// 0) disables retries - connectWithRetry tries exactly once, matching
+// every driver's previous hard-coded "open once, fail on any error"
+// behavior.
+type ConnectionPolicy struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	Jitter         bool          `json:"jitter"`
+}
+
 // Config represents the database configuration
 type Config struct {
 	// Common fields
@@ -20,9 +34,70 @@ type Config struct {
 	Account   string `json:"account"`
 	Warehouse string `json:"warehouse"`
 	Role      string `json:"role"`
+	// Async enables Snowflake's asynchronous query mode so that a query ID
+	// is returned immediately and can be persisted for crash-safe resume.
+	Async bool `json:"async"`
+	// Unload enables server-side bulk unload (Snowflake COPY INTO / BigQuery
+	// EXTRACT job) instead of paging through SELECT ... LIMIT/OFFSET.
+	Unload bool `json:"unload"`
+	// UnloadStage is the Snowflake stage (e.g. "@~/sqlextract") that COPY
+	// INTO writes unloaded files to before they're GET'd locally.
+	UnloadStage string `json:"unload_stage"`
+	// GCSBucket is the destination bucket for a BigQuery EXTRACT job, e.g.
+	// "gs://my-bucket/path".
+	GCSBucket string `json:"gcs_bucket"`
+	// MaxParallelStreams is the number of BigQuery Storage Read API streams
+	// BigQueryDB.ExtractStreams requests in parallel. Defaults to 1 (a
+	// single stream) when unset.
+	MaxParallelStreams int `json:"max_parallel_streams"`
 
 	// Databricks specific
 	Workspace string `json:"workspace"`
 	Token     string `json:"token"`
 	Catalog   string `json:"catalog"`
+
+	// BatchTimeoutSeconds bounds how long a single ExtractBatch,
+	// GetTotalRows, or GetColumns call is allowed to run before its
+	// context is canceled. 0 (the default) means no per-call timeout
+	// beyond whatever the caller's context already carries.
+	BatchTimeoutSeconds int `json:"batch_timeout_seconds"`
+	// Format is the output format ExtractData falls back to when called
+	// with an empty format argument: "csv" (the default when Format is
+	// also empty), "parquet", or "arrow". Most callers (the CLI's -format
+	// flag) always pass an explicit format and never consult this field;
+	// it exists for a Config built programmatically without one.
+	Format string `json:"format"`
+
+	// Connection governs PostgresDB/MSSQLDB/SnowflakeDB's Connect retry
+	// behavior: how many times, and how long, to wait between attempts to
+	// open and ping a connection before giving up on a transient failure
+	// (a proxy reset, a Snowflake warehouse still spinning up, a Postgres
+	// failover). The zero value disables retries.
+	Connection ConnectionPolicy `json:"connection"`
+
+	// SSLMode is the Postgres libpq sslmode (e.g. "disable", "require",
+	// "verify-full"). Also part of the key a database.ConnectionRegistry
+	// pool is shared under, alongside Host/Port/User/Database, since two
+	// PostgresDB instances that disagree on it can't share a *sql.DB.
+	// Empty means "disable", matching every driver's previous hard-coded
+	// behavior.
+	SSLMode string `json:"ssl_mode"`
+	// MaxIdleConnections and MaxOpenConnections bound a PostgresDB's
+	// underlying *sql.DB pool (sql.DB.SetMaxIdleConns/SetMaxOpenConns) -
+	// or, when the connection came from a database.ConnectionRegistry,
+	// the shared pool every registrant of the same (host,port,user,
+	// sslmode,database) key draws from. 0 leaves Go's database/sql
+	// defaults in place.
+	MaxIdleConnections int `json:"max_idle_connections"`
+	MaxOpenConnections int `json:"max_open_connections"`
+
+	// OnDrift selects what ExtractData does when a driver that tracks
+	// schema snapshots (see database.SchemaDescriber) detects that
+	// table's columns changed since the last run that recorded one:
+	// "error" (the default, including when empty) fails the run with a
+	// database.SchemaDriftError; "evolve" logs the drift and proceeds -
+	// GetColumns/GetTableSchema already reflect the table's current
+	// columns, so the run itself needs no further adjustment, just a
+	// choice not to fail on the discrepancy.
+	OnDrift string `json:"on_drift"`
 }
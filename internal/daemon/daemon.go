@@ -0,0 +1,171 @@
+// Package daemon runs sqlextract on a cron schedule inside a single
+// long-running process instead of exiting after one extraction. Each
+// scheduled fire is tracked as its own state.Manager job under a fresh
+// JobID, so the lock/checkpoint machinery the rest of the package
+// already uses to make one extraction crash-safe also lets overlapping
+// fires be skipped, queued, or cancelled instead of stepping on each
+// other.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// OverlapPolicy controls what happens when a fire occurs while the
+// previous fire's job is still "running".
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops the new fire, leaving the previous run in place.
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue waits for the previous run to finish before starting.
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapCancelPrevious cancels the previous run's context and starts
+	// the new one immediately.
+	OverlapCancelPrevious OverlapPolicy = "cancel-previous"
+)
+
+// Run is the identity a Daemon hands each scheduled fire: a fresh JobID
+// and the fire's timestamp, for templating into output paths (see
+// Run.OutputPath) and for keying the fire's state.Manager row.
+type Run struct {
+	JobID     string
+	Timestamp time.Time
+}
+
+// OutputPath renders pathTemplate, a Go text/template referencing
+// .JobID and .Timestamp (e.g. "out/{{.Timestamp}}-{{.JobID}}.csv"), for
+// this run, so each fire writes to a distinct file.
+func (r Run) OutputPath(pathTemplate string) (string, error) {
+	tmpl, err := template.New("output").Parse(pathTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse output path template: %v", err)
+	}
+
+	var buf strings.Builder
+	data := struct {
+		JobID     string
+		Timestamp string
+	}{
+		JobID:     r.JobID,
+		Timestamp: r.Timestamp.Format("20060102T150405Z0700"),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render output path template: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// Daemon fires fn on a cron schedule, tracking each fire's lifecycle
+// through a state.Manager and applying an OverlapPolicy whenever a fire
+// starts while the previous one's job is still "running".
+type Daemon struct {
+	stateManager state.Manager
+	jobPrefix    string
+	schedule     string
+	policy       OverlapPolicy
+	fn           func(ctx context.Context, run Run) error
+
+	mu         sync.Mutex
+	prevJobID  string
+	cancelPrev context.CancelFunc
+}
+
+// New creates a Daemon that invokes fn on schedule - robfig/cron/v3
+// syntax, including an optional leading seconds field and "@every
+// <duration>" - applying policy on overlap. jobPrefix is used to build
+// each fire's JobID (jobPrefix-<unix-nanos>) and therefore its
+// state.Manager row key.
+func New(stateManager state.Manager, jobPrefix, schedule string, policy OverlapPolicy, fn func(ctx context.Context, run Run) error) *Daemon {
+	return &Daemon{
+		stateManager: stateManager,
+		jobPrefix:    jobPrefix,
+		schedule:     schedule,
+		policy:       policy,
+		fn:           fn,
+	}
+}
+
+// Run blocks, firing fn on d.schedule, until ctx is cancelled.
+func (d *Daemon) Run(ctx context.Context) error {
+	c := cron.New(cron.WithParser(cron.NewParser(
+		cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+	)))
+
+	if _, err := c.AddFunc(d.schedule, func() { d.fire(ctx) }); err != nil {
+		return fmt.Errorf("failed to parse schedule %q: %v", d.schedule, err)
+	}
+
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+	return nil
+}
+
+// fire runs one scheduled invocation of fn. If the previous fire's job
+// is still "running" it applies d.policy before proceeding (or, for
+// OverlapSkip, instead of proceeding).
+func (d *Daemon) fire(ctx context.Context) {
+	d.mu.Lock()
+
+	if d.prevJobID != "" {
+		prev, err := d.stateManager.GetState(ctx, d.prevJobID)
+		if err == nil && prev != nil && prev.Status == "running" {
+			switch d.policy {
+			case OverlapQueue:
+				d.mu.Unlock()
+				d.waitForCompletion(ctx, d.prevJobID)
+				d.mu.Lock()
+			case OverlapCancelPrevious:
+				if d.cancelPrev != nil {
+					d.cancelPrev()
+				}
+			default: // OverlapSkip
+				log.Printf("daemon: previous job %s is still running, skipping this fire", d.prevJobID)
+				d.mu.Unlock()
+				return
+			}
+		}
+	}
+
+	jobID := fmt.Sprintf("%s-%d", d.jobPrefix, time.Now().UnixNano())
+	runCtx, cancel := context.WithCancel(ctx)
+	d.prevJobID = jobID
+	d.cancelPrev = cancel
+	d.mu.Unlock()
+
+	run := Run{JobID: jobID, Timestamp: time.Now()}
+	if err := d.stateManager.CreateState(ctx, &state.State{JobID: jobID, Table: jobID, Status: "running", LastUpdated: time.Now()}); err != nil {
+		log.Printf("daemon: failed to create state for job %s: %v", jobID, err)
+		return
+	}
+
+	if err := d.fn(runCtx, run); err != nil {
+		log.Printf("daemon: job %s failed: %v", jobID, err)
+		d.stateManager.UpdateStatus(ctx, jobID, "failed", err.Error())
+		return
+	}
+	d.stateManager.UpdateStatus(ctx, jobID, "completed", "")
+}
+
+// waitForCompletion polls jobID's state until it's no longer "running".
+func (d *Daemon) waitForCompletion(ctx context.Context, jobID string) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		s, err := d.stateManager.GetState(ctx, jobID)
+		if err != nil || s == nil || s.Status != "running" {
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+)
+
+// defaultExtractArrow adapts the row-based ExtractBatch path so that
+// database/sql-backed drivers (which have no native Arrow source) still
+// satisfy the Database interface's ExtractArrow method. It pages through
+// ExtractBatch, builds an arrow.Record per batch from the returned
+// []map[string]interface{} rows, and streams each record into w. Every
+// column is materialized as a string; this preserves correctness but not
+// the typed precision a native Arrow path (see SnowflakeDB, BigQueryDB)
+// would give decimals and timestamps.
+func defaultExtractArrow(ctx context.Context, db Database, table string, w *pqarrow.FileWriter, batchSize int64) error {
+	columns, err := db.GetColumns(ctx, table)
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %v", err)
+	}
+
+	totalRows, err := db.GetTotalRows(ctx, table)
+	if err != nil {
+		return fmt.Errorf("failed to get total rows: %v", err)
+	}
+
+	fields := make([]arrow.Field, len(columns))
+	for i, col := range columns {
+		fields[i] = arrow.Field{Name: col, Type: arrow.BinaryTypes.String, Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+	pool := memory.NewGoAllocator()
+
+	for offset := int64(0); offset < totalRows; offset += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows, err := db.ExtractBatch(ctx, table, offset, batchSize, "", "", "auto")
+		if err != nil {
+			return fmt.Errorf("failed to extract batch: %v", err)
+		}
+
+		record := rowsToArrowRecord(pool, schema, columns, rows)
+		err = w.WriteBuffered(record)
+		record.Release()
+		if err != nil {
+			return fmt.Errorf("failed to write arrow record: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// rowsToArrowRecord converts a batch of map-encoded rows into a single
+// Arrow record with one string column per entry in columns.
+func rowsToArrowRecord(pool memory.Allocator, schema *arrow.Schema, columns []string, rows []map[string]interface{}) arrow.Record {
+	builder := array.NewRecordBuilder(pool, schema)
+	defer builder.Release()
+
+	for _, row := range rows {
+		for i, col := range columns {
+			sb := builder.Field(i).(*array.StringBuilder)
+			if val := row[col]; val == nil {
+				sb.AppendNull()
+			} else {
+				sb.Append(fmt.Sprintf("%v", val))
+			}
+		}
+	}
+
+	return builder.NewRecord()
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Handle identifies a statement submitted via SubmitAsync so a later,
+// possibly restarted, process can reattach to it with Poll instead of
+// blocking the original call or starting over. Drivers with a native
+// async/job API (Snowflake query ID, BigQuery job ID) use that ID as the
+// Handle; callers are expected to persist it through state.Manager.
+type Handle string
+
+// Status reports the current state of a statement submitted via SubmitAsync.
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// localAsyncJob tracks an in-flight goroutine-wrapped query for drivers
+// with no native async/job API of their own.
+type localAsyncJob struct {
+	mu     sync.Mutex
+	status Status
+	rows   []map[string]interface{}
+	err    error
+}
+
+var (
+	localAsyncSeq  uint64
+	localAsyncMu   sync.Mutex
+	localAsyncJobs = map[Handle]*localAsyncJob{}
+)
+
+// defaultSubmitAsync runs query (via runSync) in a background goroutine and
+// returns a Handle that defaultPoll can later check on. It's the fallback
+// SubmitAsync implementation for drivers without a native async/job API.
+func defaultSubmitAsync(runSync func() ([]map[string]interface{}, error)) Handle {
+	seq := atomic.AddUint64(&localAsyncSeq, 1)
+	handle := Handle(fmt.Sprintf("local-%d", seq))
+
+	job := &localAsyncJob{status: StatusRunning}
+	localAsyncMu.Lock()
+	localAsyncJobs[handle] = job
+	localAsyncMu.Unlock()
+
+	go func() {
+		rows, err := runSync()
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		job.rows = rows
+		if err != nil {
+			job.status = StatusFailed
+			job.err = err
+		} else {
+			job.status = StatusDone
+		}
+	}()
+
+	return handle
+}
+
+// defaultPoll reports the status of a Handle previously returned by
+// defaultSubmitAsync. It is not resumable across process restarts, since
+// the goroutine it tracks lives only in this process's memory; drivers
+// that need restart-safe async execution (Snowflake, BigQuery) implement
+// SubmitAsync/Poll natively instead of calling this.
+func defaultPoll(handle Handle) (Status, []map[string]interface{}, error) {
+	localAsyncMu.Lock()
+	job, ok := localAsyncJobs[handle]
+	localAsyncMu.Unlock()
+	if !ok {
+		return StatusFailed, nil, fmt.Errorf("unknown async handle: %s", handle)
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.status, job.rows, job.err
+}
+
+// scanSQLRows drains a *sql.Rows into the row-map shape used throughout the
+// package. Shared by the drivers that fall back to defaultSubmitAsync.
+func scanSQLRows(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %v", err)
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+
+		row := make(map[string]interface{})
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// defaultChecksumTable adapts the row-based ExtractBatch path so that
+// drivers without a native per-row hash aggregate still satisfy the
+// Database interface's ChecksumTable method. It pages through
+// ExtractBatch, folding each row's columns (sorted by name, so column
+// order returned by the driver doesn't matter) into a CRC32 and
+// combining rows with XOR, which - unlike concatenation - doesn't depend
+// on the order rows happen to come back in.
+func defaultChecksumTable(ctx context.Context, db Database, table, keyColumns, whereClause string) (string, error) {
+	totalRows, err := db.GetTotalRows(ctx, table)
+	if err != nil {
+		return "", fmt.Errorf("failed to get total rows: %v", err)
+	}
+
+	const checksumBatchSize = 10000
+	var rowCount int64
+	var acc uint32
+	for offset := int64(0); offset < totalRows; offset += checksumBatchSize {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		rows, err := db.ExtractBatch(ctx, table, offset, checksumBatchSize, keyColumns, whereClause, "auto")
+		if err != nil {
+			return "", fmt.Errorf("failed to extract batch for checksum: %v", err)
+		}
+		for _, row := range rows {
+			acc ^= crc32.ChecksumIEEE([]byte(encodeRow(row)))
+			rowCount++
+		}
+	}
+
+	return fmt.Sprintf("%d:%08x", rowCount, acc), nil
+}
+
+// encodeRow renders row as a deterministic, column-order-independent
+// string (columns sorted by name) so two encodings of the same row
+// always hash to the same CRC32 regardless of the map iteration or
+// driver column order it came from.
+func encodeRow(row map[string]interface{}) string {
+	columns := make([]string, 0, len(row))
+	for col := range row {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	var b strings.Builder
+	for _, col := range columns {
+		if val := row[col]; val != nil {
+			fmt.Fprintf(&b, "%s=%v\x1f", col, val)
+		} else {
+			fmt.Fprintf(&b, "%s=\x1f", col)
+		}
+	}
+	return b.String()
+}
+
+// manifestBuilder accumulates the row count, checksum, and key range for
+// one output part while ExtractData streams rows to its sink, so it can
+// be turned into a state.ManifestPart once the extraction finishes.
+// Folding rows through encodeRow/CRC32/XOR keeps it comparable to a
+// defaultChecksumTable computed over the same rows.
+type manifestBuilder struct {
+	path     string
+	rowCount int64
+	checksum uint32
+	minKey   []interface{}
+	maxKey   []interface{}
+	batches  []state.BatchChecksum
+}
+
+func newManifestBuilder(path string) *manifestBuilder {
+	return &manifestBuilder{path: path}
+}
+
+// addRow folds row into the running checksum and row count, and - when
+// key is non-nil - extends the observed key range. Rows are assumed to
+// arrive in key order (as ExtractBatch's keyset path guarantees), so the
+// first key seen is the minimum and the last is the maximum.
+func (b *manifestBuilder) addRow(row map[string]interface{}, key []interface{}) {
+	b.checksum ^= crc32.ChecksumIEEE([]byte(encodeRow(row)))
+	b.rowCount++
+	if key != nil {
+		if b.minKey == nil {
+			b.minKey = key
+		}
+		b.maxKey = key
+	}
+}
+
+// addBatch records bc as one more batch written to this part, in write
+// order, so it can be carried into the final state.ManifestPart for
+// internal/verify to recompute later.
+func (b *manifestBuilder) addBatch(bc state.BatchChecksum) {
+	b.batches = append(b.batches, bc)
+}
+
+// part returns the state.ManifestPart recorded for this output so far.
+func (b *manifestBuilder) part() state.ManifestPart {
+	return state.ManifestPart{
+		Path:     b.path,
+		RowCount: b.rowCount,
+		MinKey:   b.minKey,
+		MaxKey:   b.maxKey,
+		Checksum: fmt.Sprintf("%d:%08x", b.rowCount, b.checksum),
+		Batches:  b.batches,
+	}
+}
+
+// sha256BatchChecksum hashes the raw bytes written for one ExtractBatch
+// page into a state.BatchChecksum. Unlike defaultChecksumTable's CRC32
+// (which folds rows so it's comparable regardless of driver column
+// order), this hashes the bytes actually on disk, since its purpose is
+// for internal/verify to confirm the output file wasn't corrupted or
+// truncated after the fact, not to compare against a freshly-queried
+// source table.
+func sha256BatchChecksum(data []byte, offset int64, rowCount int64, keyStart, keyEnd []interface{}) state.BatchChecksum {
+	sum := sha256.Sum256(data)
+	return state.BatchChecksum{
+		Offset:        offset,
+		RowCount:      rowCount,
+		ByteCount:     int64(len(data)),
+		SHA256:        hex.EncodeToString(sum[:]),
+		KeyRangeStart: keyStart,
+		KeyRangeEnd:   keyEnd,
+	}
+}
+
+// resumeOffset returns the offset just past the last batch in batches
+// (in write order), so a restarted extraction picks up from there
+// instead of from State.ProcessedRows, which can point past rows that
+// were counted toward progress but never confirmed written to disk
+// before a crash.
+func resumeOffset(batches []state.BatchChecksum) int64 {
+	var offset int64
+	for _, bc := range batches {
+		if end := bc.Offset + bc.RowCount; end > offset {
+			offset = end
+		}
+	}
+	return offset
+}
+
+// rowKey extracts the key-column values from row, in the order listed by
+// the comma-separated keyColumns, for use as a manifestBuilder key. It
+// returns nil when keyColumns is empty, since the row's position in
+// keyspace is then unknown.
+func rowKey(row map[string]interface{}, keyColumns string) []interface{} {
+	if keyColumns == "" {
+		return nil
+	}
+
+	keys := strings.Split(keyColumns, ",")
+	key := make([]interface{}, len(keys))
+	for i, k := range keys {
+		key[i] = row[strings.TrimSpace(k)]
+	}
+	return key
+}
@@ -0,0 +1,275 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+)
+
+// ColumnarFormatter accumulates the rows ExtractData pages through
+// ExtractBatch into a typed Arrow record per batch, for an output format
+// whose file layout needs to see every row before it can be closed out
+// (Parquet's footer references every row group; an Arrow IPC stream ends
+// with an end-of-stream marker). That rules out CSV's per-batch
+// sink.Write streaming, so a ColumnarFormatter is built up in memory over
+// the whole extraction and only flushed once, via Bytes, when it's done.
+type ColumnarFormatter interface {
+	// AddBatch infers a column schema from the first batch it sees and
+	// converts every later batch to match it, appending one Arrow record.
+	AddBatch(columns []string, rows []map[string]interface{}) error
+	// Bytes closes the underlying writer and returns the finished file.
+	Bytes() ([]byte, error)
+}
+
+// NewColumnarFormatter returns the ColumnarFormatter for format ("parquet"
+// or "arrow"), or nil if format needs no such buffering (e.g. "csv").
+// declared carries the table's SQL column types from columnSchema, if the
+// driver implements SchemaDescriber, so the Parquet/Arrow schema gets a
+// proper logical type up front instead of waiting to see a non-nil value
+// in the first batch; pass nil when the driver doesn't implement it.
+func NewColumnarFormatter(format string, declared []Column) ColumnarFormatter {
+	switch format {
+	case "parquet":
+		return &parquetFormatter{declared: declared}
+	case "arrow":
+		return &arrowFormatter{declared: declared}
+	default:
+		return nil
+	}
+}
+
+// sqlTypeToArrow maps a driver-declared SQL column type (e.g. Postgres's
+// information_schema.columns.data_type) to the Arrow type columnarSchema
+// builds a field from, so Parquet/Arrow output gets a proper logical type
+// (INT64, DOUBLE, BOOLEAN, TIMESTAMP_MILLIS/us, UTF8) without waiting to
+// see a non-nil value in the first batch. Returns nil for an empty or
+// unrecognized type, so the caller falls back to sniffing firstBatch.
+func sqlTypeToArrow(sqlType string) arrow.DataType {
+	switch strings.ToLower(sqlType) {
+	case "smallint", "integer", "int", "int2", "int4", "int8", "bigint", "tinyint", "serial", "bigserial":
+		return arrow.PrimitiveTypes.Int64
+	case "real", "double precision", "float", "float4", "float8", "numeric", "decimal", "money":
+		return arrow.PrimitiveTypes.Float64
+	case "boolean", "bool", "bit":
+		return arrow.FixedWidthTypes.Boolean
+	case "timestamp", "timestamp without time zone", "timestamp with time zone", "date", "datetime", "datetime2", "smalldatetime":
+		return arrow.FixedWidthTypes.Timestamp_us
+	case "text", "character varying", "varchar", "char", "character", "citext", "nvarchar", "nchar", "ntext":
+		return arrow.BinaryTypes.String
+	default:
+		return nil
+	}
+}
+
+// columnarSchema builds one arrow.Field per column, preferring declared's
+// SQL type (via sqlTypeToArrow) when one is known for that column, and
+// otherwise inferring it from the first non-nil value seen for it in
+// firstBatch, defaulting to Utf8 when neither is available. Every later
+// batch is coerced to match this schema rather than re-inferring it,
+// since a record's columns can't change type partway through a Parquet
+// file or Arrow IPC stream.
+func columnarSchema(columns []string, firstBatch []map[string]interface{}, declared []Column) *arrow.Schema {
+	declaredType := make(map[string]string, len(declared))
+	for _, c := range declared {
+		if c.Type != "" {
+			declaredType[c.Name] = c.Type
+		}
+	}
+
+	fields := make([]arrow.Field, len(columns))
+	for i, col := range columns {
+		if dt := sqlTypeToArrow(declaredType[col]); dt != nil {
+			fields[i] = arrow.Field{Name: col, Type: dt, Nullable: true}
+			continue
+		}
+
+		var dt arrow.DataType = arrow.BinaryTypes.String
+		for _, row := range firstBatch {
+			v := row[col]
+			if v == nil {
+				continue
+			}
+			switch v.(type) {
+			case int64, int32, int:
+				dt = arrow.PrimitiveTypes.Int64
+			case float64, float32:
+				dt = arrow.PrimitiveTypes.Float64
+			case bool:
+				dt = arrow.FixedWidthTypes.Boolean
+			case time.Time:
+				dt = arrow.FixedWidthTypes.Timestamp_us
+			default:
+				dt = arrow.BinaryTypes.String
+			}
+			break
+		}
+		fields[i] = arrow.Field{Name: col, Type: dt, Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// buildRecord converts rows into an arrow.Record matching schema, coercing
+// any value that doesn't match its column's inferred type to a null
+// (numeric/bool/timestamp columns) or its fmt.Sprintf text (string
+// columns) instead of panicking on a mismatched append.
+func buildRecord(schema *arrow.Schema, rows []map[string]interface{}) arrow.Record {
+	mem := memory.NewGoAllocator()
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+
+	for i, field := range schema.Fields() {
+		fb := b.Field(i)
+		for _, row := range rows {
+			v := row[field.Name]
+			appendColumnValue(fb, field.Type, v)
+		}
+	}
+	return b.NewRecord()
+}
+
+func appendColumnValue(fb array.Builder, dt arrow.DataType, v interface{}) {
+	if v == nil {
+		fb.AppendNull()
+		return
+	}
+	switch dt {
+	case arrow.PrimitiveTypes.Int64:
+		n, ok := toInt64Value(v)
+		if !ok {
+			fb.AppendNull()
+			return
+		}
+		fb.(*array.Int64Builder).Append(n)
+	case arrow.PrimitiveTypes.Float64:
+		f, ok := toFloat64Value(v)
+		if !ok {
+			fb.AppendNull()
+			return
+		}
+		fb.(*array.Float64Builder).Append(f)
+	case arrow.FixedWidthTypes.Boolean:
+		bv, ok := v.(bool)
+		if !ok {
+			fb.AppendNull()
+			return
+		}
+		fb.(*array.BooleanBuilder).Append(bv)
+	case arrow.FixedWidthTypes.Timestamp_us:
+		t, ok := v.(time.Time)
+		if !ok {
+			fb.AppendNull()
+			return
+		}
+		fb.(*array.TimestampBuilder).Append(arrow.Timestamp(t.UnixMicro()))
+	default:
+		fb.(*array.StringBuilder).Append(fmt.Sprintf("%v", v))
+	}
+}
+
+func toInt64Value(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func toFloat64Value(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// parquetFormatter writes Parquet via pqarrow, the pure-Go bridge from
+// Arrow records to the Parquet file format - no CGO involved, unlike the
+// DuckDB driver itself, so this is the fallback every platform can build,
+// even ones where marcboeker/go-duckdb won't.
+type parquetFormatter struct {
+	buf      bytes.Buffer
+	writer   *pqarrow.FileWriter
+	schema   *arrow.Schema
+	declared []Column
+}
+
+func (p *parquetFormatter) AddBatch(columns []string, rows []map[string]interface{}) error {
+	if p.writer == nil {
+		p.schema = columnarSchema(columns, rows, p.declared)
+		props := parquet.NewWriterProperties(
+			parquet.WithCompression(compress.Codecs.Zstd),
+			parquet.WithMaxRowGroupLength(100000),
+		)
+		writer, err := pqarrow.NewFileWriter(p.schema, &p.buf, props, pqarrow.DefaultWriterProps())
+		if err != nil {
+			return fmt.Errorf("failed to open parquet writer: %v", err)
+		}
+		p.writer = writer
+	}
+
+	rec := buildRecord(p.schema, rows)
+	defer rec.Release()
+	if err := p.writer.WriteBuffered(rec); err != nil {
+		return fmt.Errorf("failed to write parquet row group: %v", err)
+	}
+	return nil
+}
+
+func (p *parquetFormatter) Bytes() ([]byte, error) {
+	if p.writer == nil {
+		return nil, nil
+	}
+	if err := p.writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close parquet writer: %v", err)
+	}
+	return p.buf.Bytes(), nil
+}
+
+// arrowFormatter writes the Arrow IPC stream format, for a downstream
+// consumer that wants typed columns (and real NULLs) without paying
+// Parquet's encoding/footer overhead.
+type arrowFormatter struct {
+	buf      bytes.Buffer
+	writer   *ipc.Writer
+	schema   *arrow.Schema
+	declared []Column
+}
+
+func (a *arrowFormatter) AddBatch(columns []string, rows []map[string]interface{}) error {
+	if a.writer == nil {
+		a.schema = columnarSchema(columns, rows, a.declared)
+		a.writer = ipc.NewWriter(&a.buf, ipc.WithSchema(a.schema))
+	}
+
+	rec := buildRecord(a.schema, rows)
+	defer rec.Release()
+	if err := a.writer.Write(rec); err != nil {
+		return fmt.Errorf("failed to write arrow record batch: %v", err)
+	}
+	return nil
+}
+
+func (a *arrowFormatter) Bytes() ([]byte, error) {
+	if a.writer == nil {
+		return nil, nil
+	}
+	if err := a.writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close arrow writer: %v", err)
+	}
+	return a.buf.Bytes(), nil
+}
@@ -1,5 +1,7 @@
 package database
 
+import "context"
+
 // Config represents database configuration
 type Config struct {
 	Type            string
@@ -20,3 +22,23 @@ type Column struct {
 	Name string
 	Type string
 }
+
+// SchemaDescriber is implemented by drivers that can report each column's
+// declared source type alongside its name (PostgresDB, DuckDB), unlike
+// the base Database interface's GetColumns, which only returns names.
+// Callers that need types - e.g. internal/catalog registering a Glue
+// schema - should fall back to GetColumns with an unknown/string type
+// when a driver doesn't implement this.
+type SchemaDescriber interface {
+	GetTableSchema(ctx context.Context, table string) ([]Column, error)
+}
+
+// PrimaryKeyDiscoverer is implemented by drivers that can report a table's
+// primary key column(s) (PostgresDB today), letting callers default to
+// keyset pagination without the user having to pass -keys explicitly.
+// The returned string is comma-separated in ordinal order, ready to use
+// as ExtractBatch/ExtractData's keyColumns argument, and is empty (with a
+// nil error) when table has no primary key.
+type PrimaryKeyDiscoverer interface {
+	GetPrimaryKey(ctx context.Context, table string) (string, error)
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gerhard-ee/sqlextract/internal/config"
 	"github.com/gerhard-ee/sqlextract/internal/state"
@@ -42,7 +44,8 @@ func TestDatabaseOperations(t *testing.T) {
 	}
 
 	// Connect to database
-	if err := db.Connect(); err != nil {
+	ctx := context.Background()
+	if err := db.Connect(ctx); err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
@@ -55,7 +58,7 @@ func TestDatabaseOperations(t *testing.T) {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)
 	`
-	if err := db.Exec(context.Background(), query); err != nil {
+	if err := db.Exec(ctx, query); err != nil {
 		t.Fatalf("Failed to create test table: %v", err)
 	}
 
@@ -65,7 +68,7 @@ func TestDatabaseOperations(t *testing.T) {
 			INSERT INTO test_table (id, name, age)
 			VALUES (%d, 'Test User %d', %d)
 		`, i+1, i, 20+i)
-		if err := db.Exec(context.Background(), query); err != nil {
+		if err := db.Exec(ctx, query); err != nil {
 			t.Fatalf("Failed to insert test data: %v", err)
 		}
 	}
@@ -79,15 +82,20 @@ func TestDatabaseOperations(t *testing.T) {
 	// Test error handling
 	testErrorHandling(t, db, testDir)
 
+	// Test keyset pagination
+	testKeysetPagination(t, db, stateManager)
+
 	// Clean up
-	if err := db.Close(); err != nil {
+	if err := db.Close(ctx); err != nil {
 		t.Errorf("Failed to close database: %v", err)
 	}
 }
 
 func testBasicOperations(t *testing.T, db Database, testDir string) {
+	ctx := context.Background()
+
 	// Test GetTotalRows
-	totalRows, err := db.GetTotalRows("test_table")
+	totalRows, err := db.GetTotalRows(ctx, "test_table")
 	if err != nil {
 		t.Errorf("GetTotalRows failed: %v", err)
 		return
@@ -98,7 +106,7 @@ func testBasicOperations(t *testing.T, db Database, testDir string) {
 	}
 
 	// Test GetColumns
-	columns, err := db.GetColumns("test_table")
+	columns, err := db.GetColumns(ctx, "test_table")
 	if err != nil {
 		t.Errorf("GetColumns failed: %v", err)
 		return
@@ -109,7 +117,7 @@ func testBasicOperations(t *testing.T, db Database, testDir string) {
 	}
 
 	// Test ExtractBatch
-	rows, err := db.ExtractBatch("test_table", 0, 1000, "", "")
+	rows, err := db.ExtractBatch(ctx, "test_table", 0, 1000, "", "", "auto")
 	if err != nil {
 		t.Errorf("ExtractBatch failed: %v", err)
 		return
@@ -140,11 +148,13 @@ func testBasicOperations(t *testing.T, db Database, testDir string) {
 }
 
 func testConcurrentOperations(t *testing.T, db Database, testDir string) {
+	ctx := context.Background()
+
 	// Test concurrent reads
 	errChan := make(chan error, 5)
 	for i := 0; i < 5; i++ {
 		go func() {
-			_, err := db.ExtractBatch("test_table", 0, 1000, "", "")
+			_, err := db.ExtractBatch(ctx, "test_table", 0, 1000, "", "", "auto")
 			errChan <- err
 		}()
 	}
@@ -158,19 +168,98 @@ func testConcurrentOperations(t *testing.T, db Database, testDir string) {
 }
 
 func testErrorHandling(t *testing.T, db Database, testDir string) {
+	ctx := context.Background()
+
 	// Test non-existent table
-	_, err := db.GetTotalRows("non_existent_table")
+	_, err := db.GetTotalRows(ctx, "non_existent_table")
 	if err == nil {
 		t.Error("Expected error for non-existent table")
 	}
 
 	// Test invalid batch size
-	_, err = db.ExtractBatch("test_table", 0, -1, "", "")
+	_, err = db.ExtractBatch(ctx, "test_table", 0, -1, "", "", "auto")
 	if err == nil {
 		t.Error("Expected error for invalid batch size")
 	}
 }
 
+// testKeysetPagination pages through test_table by its "id" primary key
+// with resumeMode "keyset" while rows keep landing at the tail
+// concurrently, and asserts every id is seen exactly once with no gaps -
+// the property OFFSET/LIMIT can't guarantee once rows are inserted mid-scan,
+// since a growing table shifts every row after the insert point by one
+// offset and either skips or repeats it depending on timing.
+func testKeysetPagination(t *testing.T, db Database, stateManager state.Manager) {
+	ctx := context.Background()
+	if err := stateManager.CreateState(ctx, &state.State{Table: "test_table", Status: "running"}); err != nil {
+		t.Fatalf("Failed to create state for keyset pagination: %v", err)
+	}
+
+	const tailInserts = 50
+	const totalRows = 100 + tailInserts
+	const batchSize = 7
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < tailInserts; i++ {
+			id := 100 + i + 1
+			query := fmt.Sprintf(`INSERT INTO test_table (id, name, age) VALUES (%d, 'Tail User %d', %d)`, id, id, 30)
+			if err := db.Exec(ctx, query); err != nil {
+				t.Errorf("Failed to insert tail row %d: %v", id, err)
+				return
+			}
+		}
+	}()
+
+	seen := make(map[int64]bool)
+	const maxEmptyBatches = 2000
+	emptyBatches := 0
+	for {
+		rows, err := db.ExtractBatch(ctx, "test_table", 0, batchSize, "id", "", "keyset")
+		if err != nil {
+			t.Fatalf("Keyset ExtractBatch failed: %v", err)
+		}
+		if len(rows) == 0 {
+			if len(seen) >= totalRows {
+				break
+			}
+			emptyBatches++
+			if emptyBatches > maxEmptyBatches {
+				t.Fatalf("gave up waiting for tail inserts: saw %d/%d rows", len(seen), totalRows)
+			}
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		emptyBatches = 0
+		for _, row := range rows {
+			id, ok := row["id"].(int64)
+			if !ok {
+				if id32, ok32 := row["id"].(int32); ok32 {
+					id = int64(id32)
+				} else {
+					t.Fatalf("unexpected id type %T for row %v", row["id"], row)
+				}
+			}
+			if seen[id] {
+				t.Fatalf("row with id %d was extracted more than once", id)
+			}
+			seen[id] = true
+		}
+	}
+	wg.Wait()
+
+	if len(seen) != totalRows {
+		t.Fatalf("expected to see %d rows, saw %d", totalRows, len(seen))
+	}
+	for id := int64(1); id <= totalRows; id++ {
+		if !seen[id] {
+			t.Fatalf("gap in keyset pagination: row with id %d was never extracted", id)
+		}
+	}
+}
+
 func writeCSVHeader(f *os.File, columns []string) error {
 	_, err := fmt.Fprintf(f, "%s\n", columns)
 	return err
@@ -1,15 +1,19 @@
 package database
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+
 	_ "github.com/databricks/databricks-sql-go"
 	"github.com/gerhard-ee/sqlextract/internal/config"
+	"github.com/gerhard-ee/sqlextract/internal/metrics"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
 	"github.com/gerhard-ee/sqlextract/internal/state"
 )
 
@@ -24,13 +28,13 @@ func NewDatabricks(cfg *config.Config, stateManager state.Manager) (Database, er
 		config:       cfg,
 		stateManager: stateManager,
 	}
-	if err := db.Connect(); err != nil {
+	if err := db.Connect(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to connect to Databricks: %v", err)
 	}
 	return db, nil
 }
 
-func (db *DatabricksDB) Connect() error {
+func (db *DatabricksDB) Connect(ctx context.Context) error {
 	// Databricks connection string format:
 	// "databricks://token:<access_token>@<host>:443/default?catalog=<catalog>&schema=<schema>"
 	connStr := fmt.Sprintf("databricks://token:%s@%s:443/%s?catalog=%s&schema=%s",
@@ -49,107 +53,230 @@ func (db *DatabricksDB) Connect() error {
 	return nil
 }
 
-func (db *DatabricksDB) Close() error {
+func (db *DatabricksDB) Close(ctx context.Context) error {
 	if db.db != nil {
 		return db.db.Close()
 	}
 	return nil
 }
 
-func (db *DatabricksDB) ExtractData(table, outputFile, format string, batchSize int) error {
-	// Get current state
-	currentState, err := db.stateManager.GetState(table)
+func (db *DatabricksDB) Exec(ctx context.Context, query string) error {
+	_, err := db.db.ExecContext(ctx, query)
 	if err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+	return nil
+}
+
+func (db *DatabricksDB) ExtractData(ctx context.Context, table, outputFile, format string, batchSize int, keyColumns, whereClause, resumeMode string) (err error) {
+	// An empty format falls back to Config.Format, then "csv", so a
+	// caller that builds Config programmatically without setting a
+	// per-call format still gets well-defined behavior.
+	if format == "" {
+		format = db.config.Format
+	}
+	if format == "" {
+		format = "csv"
+	}
+
+	if db.config.Unload && sink.IsRemote(outputFile) {
+		return db.unloadViaDirectory(ctx, table, outputFile, format)
+	}
+
+	// Get current state
+	currentState, err := db.stateManager.GetState(ctx, table)
+	if err != nil || currentState == nil {
 		// Create new state if it doesn't exist
 		currentState = &state.State{
 			Table:       table,
 			LastUpdated: time.Now(),
 			Status:      "running",
 		}
-		if err := db.stateManager.CreateState(currentState); err != nil {
+		if err := db.stateManager.CreateState(ctx, currentState); err != nil {
 			return fmt.Errorf("failed to create state: %v", err)
 		}
+	} else if currentState.Status == "completed" {
+		// A crash-safe checkpoint: a previous run already finished this
+		// extraction, so there's nothing left to resume.
+		return nil
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
-	}
+	// A restarted process lands here after a crash mid-extraction; mark
+	// the outcome so the next run (or an operator) can tell completed,
+	// failed, and still-in-progress apart. This uses context.Background()
+	// so the status still gets recorded even if ctx was what caused err.
+	defer func() {
+		if err != nil {
+			db.stateManager.UpdateStatus(context.Background(), table, "failed", err.Error())
+		} else {
+			db.stateManager.UpdateStatus(context.Background(), table, "completed", "")
+		}
+	}()
 
 	// Get total rows
-	totalRows, err := db.GetTotalRows(table)
+	totalRows, err := db.GetTotalRows(ctx, table)
 	if err != nil {
 		return fmt.Errorf("failed to get total rows: %v", err)
 	}
 
 	// Get columns
-	columns, err := db.GetColumns(table)
+	columns, err := db.GetColumns(ctx, table)
 	if err != nil {
 		return fmt.Errorf("failed to get columns: %v", err)
 	}
 
-	// Create output file
-	file, err := os.Create(outputFile)
+	// outputFile may be a local path or an s3://, gs://, abfs:// URI; sink
+	// picks the right implementation and streams each batch to it as a
+	// rolling upload instead of requiring local disk equal to table size.
+	out, err := sink.New(outputFile)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return fmt.Errorf("failed to open output sink: %v", err)
 	}
-	defer file.Close()
 
-	// Write header if CSV format
-	if format == "csv" {
-		if _, err := fmt.Fprintf(file, "%s\n", strings.Join(columns, ",")); err != nil {
+	// schemaColumns carries declared SQL types when db implements
+	// SchemaDescriber, so the header/row writer and ColumnarFormatter
+	// both get a proper type hint instead of sniffing values.
+	schemaColumns := namedColumns(columns, columnSchema(ctx, db, table))
+
+	var hdr bytes.Buffer
+	if hw := NewRowWriter(format, &hdr); hw != nil {
+		if err := hw.WriteHeader(schemaColumns); err != nil {
 			return fmt.Errorf("failed to write header: %v", err)
 		}
+		if err := hw.Close(); err != nil {
+			return fmt.Errorf("failed to write header: %v", err)
+		}
+		if hdr.Len() > 0 {
+			if err := out.Write(ctx, &hdr); err != nil {
+				return fmt.Errorf("failed to write header: %v", err)
+			}
+		}
 	}
 
-	// Process data in batches
+	// Parquet and Arrow IPC both end with a footer/EOS marker that has to
+	// see every row, so - unlike CSV - cf builds them up in memory across
+	// the whole extraction and they're flushed once after the loop,
+	// instead of being streamed to out batch by batch.
+	cf := NewColumnarFormatter(format, schemaColumns)
+
+	// Process data in batches. A restart after a crash resumes just past
+	// the last batch RecordBatch confirmed landed on disk, rather than at
+	// currentState.ProcessedRows, since a row offset can shift under a
+	// batch that's already been written if the source table is being
+	// written to concurrently.
 	processedRows := int64(0)
-	for offset := int64(0); offset < totalRows; offset += int64(batchSize) {
-		rows, err := db.ExtractBatch(table, offset, int64(batchSize))
+	mb := newManifestBuilder(outputFile)
+	for _, bc := range currentState.Batches {
+		mb.addBatch(bc)
+		processedRows += bc.RowCount
+	}
+	for offset := resumeOffset(currentState.Batches); offset < totalRows; offset += int64(batchSize) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batchStart := time.Now()
+		rows, err := db.ExtractBatch(ctx, table, offset, int64(batchSize), keyColumns, whereClause, resumeMode)
 		if err != nil {
 			return fmt.Errorf("failed to extract batch: %v", err)
 		}
 
 		// Write rows
-		for _, row := range rows {
-			if format == "csv" {
-				values := make([]string, len(columns))
-				for i, col := range columns {
-					if val := row[col]; val == nil {
-						values[i] = "NULL"
-					} else {
-						values[i] = fmt.Sprintf("%v", val)
-					}
-				}
-				if _, err := fmt.Fprintf(file, "%s\n", strings.Join(values, ",")); err != nil {
-					return fmt.Errorf("failed to write row: %v", err)
+		var batch bytes.Buffer
+		rw := NewRowWriter(format, &batch)
+		var keyStart, keyEnd []interface{}
+		for rowIdx, row := range rows {
+			if rw != nil {
+				if err := rw.WriteRow(schemaColumns, row); err != nil {
+					return fmt.Errorf("failed to encode row: %v", err)
 				}
 			}
+			key := rowKey(row, keyColumns)
+			if rowIdx == 0 {
+				keyStart = key
+			}
+			keyEnd = key
+			mb.addRow(row, key)
 			processedRows++
 		}
+		if rw != nil {
+			if err := rw.Close(); err != nil {
+				return fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		if cf != nil {
+			if err := cf.AddBatch(columns, rows); err != nil {
+				return fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		batchBytes := batch.Len()
+		bc := sha256BatchChecksum(batch.Bytes(), offset, int64(len(rows)), keyStart, keyEnd)
+		if cf == nil {
+			if err := out.Write(ctx, &batch); err != nil {
+				return fmt.Errorf("failed to write batch: %v", err)
+			}
+		}
+		metrics.RecordBatch(db.config.Type, table, len(rows), time.Since(batchStart), batchBytes)
+		mb.addBatch(bc)
 
 		// Update state
-		if err := db.stateManager.UpdateState(table, processedRows); err != nil {
+		if err := db.stateManager.UpdateState(ctx, table, processedRows); err != nil {
 			return fmt.Errorf("failed to update state: %v", err)
 		}
+		if err := db.stateManager.RecordBatch(ctx, table, bc); err != nil {
+			return fmt.Errorf("failed to record batch checksum: %v", err)
+		}
+	}
+
+	if cf != nil {
+		data, err := cf.Bytes()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s output: %v", format, err)
+		}
+		if err := out.Write(ctx, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to write %s output: %v", format, err)
+		}
+	}
+
+	if err := out.Finalize(); err != nil {
+		return fmt.Errorf("failed to finalize output: %v", err)
+	}
+
+	if err := finalizeManifest(ctx, db, db.stateManager, table, keyColumns, whereClause, format, outputFile, []state.ManifestPart{mb.part()}); err != nil {
+		return fmt.Errorf("failed to verify extraction: %v", err)
 	}
 
 	return nil
 }
 
-func (db *DatabricksDB) GetTotalRows(table string) (int64, error) {
+// unloadViaDirectory bypasses the JDBC-style database/sql client entirely
+// by having the SQL warehouse itself write table straight to outputFile -
+// an s3://, gs://, or abfs:// path the warehouse already has storage
+// credentials for - instead of paging rows through ExtractBatch.
+func (db *DatabricksDB) unloadViaDirectory(ctx context.Context, table, outputFile, format string) error {
+	var options string
+	if format == "csv" {
+		options = " OPTIONS (header 'true')"
+	}
+	query := fmt.Sprintf("INSERT OVERWRITE DIRECTORY '%s' USING %s%s SELECT * FROM %s", outputFile, format, options, table)
+	if _, err := db.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to unload %s to %s: %v", table, outputFile, err)
+	}
+	return nil
+}
+
+func (db *DatabricksDB) GetTotalRows(ctx context.Context, table string) (int64, error) {
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
 	var count int64
-	err := db.db.QueryRow(query).Scan(&count)
+	err := db.db.QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get row count: %v", err)
 	}
 	return count, nil
 }
 
-func (db *DatabricksDB) GetColumns(table string) ([]string, error) {
+func (db *DatabricksDB) GetColumns(ctx context.Context, table string) ([]string, error) {
 	query := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = '%s' ORDER BY ordinal_position", table)
-	rows, err := db.db.Query(query)
+	rows, err := db.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %v", err)
 	}
@@ -166,9 +293,25 @@ func (db *DatabricksDB) GetColumns(table string) ([]string, error) {
 	return columns, nil
 }
 
-func (db *DatabricksDB) ExtractBatch(table string, offset, limit int64) ([]map[string]interface{}, error) {
-	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", table, limit, offset)
-	rows, err := db.db.Query(query)
+func (db *DatabricksDB) ExtractBatch(ctx context.Context, table string, offset, limit int64, keyColumns, whereClause, resumeMode string) ([]map[string]interface{}, error) {
+	ctx, cancel := withBatchTimeout(ctx, db.config)
+	defer cancel()
+
+	if useKeyset(resumeMode, keyColumns) {
+		return db.extractBatchKeyset(ctx, table, limit, keyColumns, whereClause)
+	}
+
+	// No ordered unique key was given, so fall back to plain LIMIT/OFFSET
+	// paging. This re-scans and re-sorts the entire prefix on every batch,
+	// which is O(N^2) over a full extraction - callers should pass
+	// keyColumns whenever the table has one.
+	query := fmt.Sprintf("SELECT * FROM %s", table)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+
+	rows, err := db.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %v", err)
 	}
@@ -200,3 +343,123 @@ func (db *DatabricksDB) ExtractBatch(table string, offset, limit int64) ([]map[s
 
 	return result, nil
 }
+
+// extractBatchKeyset replaces LIMIT/OFFSET with keyset (seek) pagination,
+// tracking the last key tuple emitted in state.State.LastKey so each batch
+// only scans forward from where the previous one stopped instead of
+// rescanning and discarding `offset` rows on every call.
+func (db *DatabricksDB) extractBatchKeyset(ctx context.Context, table string, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error) {
+	keys := strings.Split(keyColumns, ",")
+	for i, k := range keys {
+		keys[i] = strings.TrimSpace(k)
+	}
+
+	currentState, err := db.stateManager.GetState(ctx, table)
+	if err != nil {
+		currentState = nil
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s", table)
+	var args []interface{}
+
+	var conditions []string
+	if whereClause != "" {
+		conditions = append(conditions, whereClause)
+	}
+	if currentState != nil && len(currentState.LastKey) == len(keys) {
+		placeholders := make([]string, len(keys))
+		for i := range keys {
+			placeholders[i] = "?"
+			args = append(args, currentState.LastKey[i])
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", strings.Join(keys, ", "), strings.Join(placeholders, ", ")))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + strings.Join(keys, ", ")
+	query += fmt.Sprintf(" LIMIT %d", limit)
+
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute keyset query: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %v", err)
+	}
+	keyIndex := make(map[string]int, len(keys))
+	for i, col := range columns {
+		keyIndex[col] = i
+	}
+
+	var result []map[string]interface{}
+	var lastValues []interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		lastValues = values
+
+		row := make(map[string]interface{})
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	if len(lastValues) > 0 {
+		lastKey := make([]interface{}, len(keys))
+		for i, k := range keys {
+			lastKey[i] = lastValues[keyIndex[k]]
+		}
+		if err := db.stateManager.UpdateLastKey(ctx, table, lastKey); err != nil {
+			return nil, fmt.Errorf("failed to persist last key: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// ExtractArrow streams table into w as Arrow record batches. Databricks has
+// no native Arrow source wired up here, so this adapts the row-based
+// ExtractBatch path.
+func (db *DatabricksDB) ExtractArrow(ctx context.Context, table string, w *pqarrow.FileWriter, batchSize int64) error {
+	return defaultExtractArrow(ctx, db, table, w, batchSize)
+}
+
+// SubmitAsync runs sql synchronously in a background goroutine via
+// defaultSubmitAsync; Poll reports on it from there. Databricks' SQL
+// warehouses do support async statement execution over their REST
+// Statement Execution API, but that's a separate client from the
+// database/sql driver used here, so it isn't wired up yet.
+func (db *DatabricksDB) SubmitAsync(ctx context.Context, sql string) (Handle, error) {
+	return defaultSubmitAsync(func() ([]map[string]interface{}, error) {
+		rows, err := db.db.QueryContext(ctx, sql)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %v", err)
+		}
+		defer rows.Close()
+		return scanSQLRows(rows)
+	}), nil
+}
+
+func (db *DatabricksDB) Poll(ctx context.Context, handle Handle) (Status, []map[string]interface{}, error) {
+	return defaultPoll(handle)
+}
+
+// ChecksumTable falls back to defaultChecksumTable. Databricks SQL has a
+// hash() function and a bit_xor aggregate that could compute this
+// server-side (mirroring Snowflake's BIT_XOR(HASH(*))), but that isn't
+// wired up here yet.
+func (db *DatabricksDB) ChecksumTable(ctx context.Context, table, keyColumns, whereClause string) (string, error) {
+	return defaultChecksumTable(ctx, db, table, keyColumns, whereClause)
+}
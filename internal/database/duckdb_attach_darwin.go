@@ -0,0 +1,82 @@
+//go:build darwin && !release
+// +build darwin,!release
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gerhard-ee/sqlextract/internal/config"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+// duckdbAttachExtract unloads table out of the source named by cfg
+// straight to outputFile by running the extraction inside an in-process
+// DuckDB instance instead of paging rows through database/sql: it ATTACHes
+// the source (Postgres today; DuckDB's MySQL scanner follows the same
+// shape for a future cfg.Type == "mysql"), installs httpfs and points it
+// at outputFile's bucket when outputFile is itself a cloud URI, then runs
+// a single COPY ... TO that streams straight to Parquet/CSV without ever
+// materializing the result set in the Go process. format selects the COPY
+// options; "arrow" isn't a COPY target DuckDB supports, so it falls back
+// to the row-by-row ExtractArrow path instead.
+func duckdbAttachExtract(ctx context.Context, cfg *config.Config, table, outputFile, format string) error {
+	if format == "arrow" {
+		return fmt.Errorf("duckdb attach export does not support the arrow format; use ExtractArrow instead")
+	}
+	if cfg.Type != "postgres" {
+		return fmt.Errorf("duckdb attach export is only wired up for postgres sources")
+	}
+
+	duck, err := sql.Open("duckdb", "")
+	if err != nil {
+		return fmt.Errorf("failed to open in-process duckdb: %v", err)
+	}
+	defer duck.Close()
+
+	attachDSN := fmt.Sprintf(
+		"host=%s port=%d dbname=%s user=%s password=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.Database, cfg.User, cfg.Password,
+	)
+	if _, err := duck.ExecContext(ctx, "INSTALL postgres; LOAD postgres;"); err != nil {
+		return fmt.Errorf("failed to load duckdb postgres extension: %v", err)
+	}
+	attachSQL := fmt.Sprintf("ATTACH '%s' AS src (TYPE POSTGRES, READ_ONLY)", attachDSN)
+	if _, err := duck.ExecContext(ctx, attachSQL); err != nil {
+		return fmt.Errorf("failed to attach postgres source: %v", err)
+	}
+
+	localOut := outputFile
+	if sink.IsRemote(outputFile) {
+		if _, err := duck.ExecContext(ctx, "INSTALL httpfs; LOAD httpfs;"); err != nil {
+			return fmt.Errorf("failed to load duckdb httpfs extension: %v", err)
+		}
+		localOut = outputFile
+	}
+
+	schema := cfg.Schema
+	if schema == "" {
+		schema = "public"
+	}
+
+	var copyOptions string
+	switch format {
+	case "parquet":
+		copyOptions = "(FORMAT PARQUET, COMPRESSION ZSTD, ROW_GROUP_SIZE 100000)"
+	default:
+		copyOptions = "(FORMAT CSV, HEADER TRUE)"
+	}
+
+	copySQL := fmt.Sprintf(
+		"COPY (SELECT * FROM src.%s.%s) TO '%s' %s",
+		schema, table, localOut, copyOptions,
+	)
+	if _, err := duck.ExecContext(ctx, copySQL); err != nil {
+		return fmt.Errorf("failed to export %s via duckdb attach: %v", table, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !darwin
+// +build !darwin
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gerhard-ee/sqlextract/internal/config"
+)
+
+// duckdbAttachExtract has no implementation on this platform: it needs
+// marcboeker/go-duckdb, which is CGO-only and built just for darwin (see
+// duckdb_darwin.go). ExtractData falls back to its row-by-row path - and,
+// for format == "parquet"/"arrow", the pure-Go ColumnarFormatter - when
+// this returns an error instead of failing the extraction outright.
+func duckdbAttachExtract(ctx context.Context, cfg *config.Config, table, outputFile, format string) error {
+	return fmt.Errorf("duckdb attach export is only available on macOS")
+}
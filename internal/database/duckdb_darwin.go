@@ -4,19 +4,24 @@
 package database
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+
+	"github.com/gerhard-ee/sqlextract/internal/metrics"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
 	"github.com/gerhard-ee/sqlextract/internal/state"
-	_ "github.com/marcboeker/go-duckdb"
+	"github.com/marcboeker/go-duckdb"
 )
 
-func (db *DuckDB) Connect() error {
+func (db *DuckDB) Connect(ctx context.Context) error {
 	conn, err := sql.Open("duckdb", db.config.Database)
 	if err != nil {
 		return fmt.Errorf("failed to connect to DuckDB: %v", err)
@@ -26,100 +31,213 @@ func (db *DuckDB) Connect() error {
 	return nil
 }
 
-func (db *DuckDB) Close() error {
+func (db *DuckDB) Close(ctx context.Context) error {
 	if sqlDB, ok := db.db.(*sql.DB); ok && sqlDB != nil {
 		return sqlDB.Close()
 	}
 	return nil
 }
 
-func (db *DuckDB) ExtractData(table, outputFile, format string, batchSize int, keyColumns, whereClause string) error {
+func (db *DuckDB) ExtractData(ctx context.Context, table, outputFile, format string, batchSize int, keyColumns, whereClause, resumeMode string) (err error) {
+	// An empty format falls back to Config.Format, then "csv", so a
+	// caller that builds Config programmatically without setting a
+	// per-call format still gets well-defined behavior.
+	if format == "" {
+		format = db.config.Format
+	}
+	if format == "" {
+		format = "csv"
+	}
+
 	sqlDB, ok := db.db.(*sql.DB)
 	if !ok || sqlDB == nil {
 		return fmt.Errorf("database connection not initialized")
 	}
 
 	// Get current state
-	currentState, err := db.stateManager.GetState(table)
-	if err != nil {
+	currentState, err := db.stateManager.GetState(ctx, table)
+	if err != nil || currentState == nil {
 		// Create new state if it doesn't exist
 		currentState = &state.State{
 			Table:       table,
 			LastUpdated: time.Now(),
 			Status:      "running",
 		}
-		if err := db.stateManager.CreateState(currentState); err != nil {
+		if err := db.stateManager.CreateState(ctx, currentState); err != nil {
 			return fmt.Errorf("failed to create state: %v", err)
 		}
+	} else if currentState.Status == "completed" {
+		// A crash-safe checkpoint: a previous run already finished this
+		// extraction, so there's nothing left to resume.
+		return nil
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
-	}
+	// A restarted process lands here after a crash mid-extraction; mark
+	// the outcome so the next run (or an operator) can tell completed,
+	// failed, and still-in-progress apart. This uses context.Background()
+	// so the status still gets recorded even if ctx was what caused err.
+	defer func() {
+		if err != nil {
+			db.stateManager.UpdateStatus(context.Background(), table, "failed", err.Error())
+		} else {
+			db.stateManager.UpdateStatus(context.Background(), table, "completed", "")
+		}
+	}()
 
 	// Get total rows
-	totalRows, err := db.GetTotalRows(table)
+	totalRows, err := db.GetTotalRows(ctx, table)
 	if err != nil {
 		return fmt.Errorf("failed to get total rows: %v", err)
 	}
 
 	// Get columns
-	columns, err := db.GetColumns(table)
+	columns, err := db.GetColumns(ctx, table)
 	if err != nil {
 		return fmt.Errorf("failed to get columns: %v", err)
 	}
 
-	// Create output file
-	file, err := os.Create(outputFile)
+	// Compare table's current schema against the snapshot the previous
+	// run that extracted it recorded, failing (or, with Config.OnDrift ==
+	// "evolve", just logging) if columns were added, removed, or retyped
+	// since then. columns/schemaColumns below already reflect the
+	// current schema regardless, so "evolve" needs no further handling.
+	drift, schemaHash, schemaSnapshot, err := checkSchemaDrift(ctx, db, table, currentState)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return fmt.Errorf("failed to check schema drift: %v", err)
+	}
+	if drift != nil && db.config.OnDrift != "evolve" {
+		return drift
 	}
-	defer file.Close()
+	if schemaHash != "" {
+		if err := db.stateManager.UpdateSchemaSnapshot(ctx, table, schemaHash, schemaSnapshot); err != nil {
+			return fmt.Errorf("failed to persist schema snapshot: %v", err)
+		}
+	}
+
+	// outputFile may be a local path or an s3://, gs://, abfs:// URI; sink
+	// picks the right implementation and streams each batch to it as a
+	// rolling upload instead of requiring local disk equal to table size.
+	out, err := sink.New(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open output sink: %v", err)
+	}
+
+	// schemaColumns carries declared SQL types when db implements
+	// SchemaDescriber, so the header/row writer and ColumnarFormatter
+	// both get a proper type hint instead of sniffing values.
+	schemaColumns := namedColumns(columns, columnSchema(ctx, db, table))
 
-	// Write header if CSV format
-	if format == "csv" {
-		if _, err := fmt.Fprintf(file, "%s\n", strings.Join(columns, ",")); err != nil {
+	var hdr bytes.Buffer
+	if hw := NewRowWriter(format, &hdr); hw != nil {
+		if err := hw.WriteHeader(schemaColumns); err != nil {
+			return fmt.Errorf("failed to write header: %v", err)
+		}
+		if err := hw.Close(); err != nil {
 			return fmt.Errorf("failed to write header: %v", err)
 		}
+		if hdr.Len() > 0 {
+			if err := out.Write(ctx, &hdr); err != nil {
+				return fmt.Errorf("failed to write header: %v", err)
+			}
+		}
 	}
 
-	// Process data in batches
+	// Parquet and Arrow IPC both end with a footer/EOS marker that has to
+	// see every row, so - unlike CSV - cf builds them up in memory across
+	// the whole extraction and they're flushed once after the loop,
+	// instead of being streamed to out batch by batch.
+	cf := NewColumnarFormatter(format, schemaColumns)
+
+	// Process data in batches. A restart after a crash resumes just past
+	// the last batch RecordBatch confirmed landed on disk, rather than at
+	// currentState.ProcessedRows, since a row offset can shift under a
+	// batch that's already been written if the source table is being
+	// written to concurrently.
 	processedRows := int64(0)
-	for offset := int64(0); offset < totalRows; offset += int64(batchSize) {
-		rows, err := db.ExtractBatch(table, offset, int64(batchSize), keyColumns, whereClause)
+	mb := newManifestBuilder(outputFile)
+	for _, bc := range currentState.Batches {
+		mb.addBatch(bc)
+		processedRows += bc.RowCount
+	}
+	for offset := resumeOffset(currentState.Batches); offset < totalRows; offset += int64(batchSize) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batchStart := time.Now()
+		rows, err := db.ExtractBatch(ctx, table, offset, int64(batchSize), keyColumns, whereClause, resumeMode)
 		if err != nil {
 			return fmt.Errorf("failed to extract batch: %v", err)
 		}
 
 		// Write rows
-		for _, row := range rows {
-			if format == "csv" {
-				values := make([]string, len(columns))
-				for i, col := range columns {
-					if val := row[col]; val == nil {
-						values[i] = "NULL"
-					} else {
-						values[i] = fmt.Sprintf("%v", val)
-					}
-				}
-				if _, err := fmt.Fprintf(file, "%s\n", strings.Join(values, ",")); err != nil {
-					return fmt.Errorf("failed to write row: %v", err)
+		var batch bytes.Buffer
+		rw := NewRowWriter(format, &batch)
+		var keyStart, keyEnd []interface{}
+		for rowIdx, row := range rows {
+			if rw != nil {
+				if err := rw.WriteRow(schemaColumns, row); err != nil {
+					return fmt.Errorf("failed to encode row: %v", err)
 				}
 			}
+			key := rowKey(row, keyColumns)
+			if rowIdx == 0 {
+				keyStart = key
+			}
+			keyEnd = key
+			mb.addRow(row, key)
 			processedRows++
 		}
+		if rw != nil {
+			if err := rw.Close(); err != nil {
+				return fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		if cf != nil {
+			if err := cf.AddBatch(columns, rows); err != nil {
+				return fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		batchBytes := batch.Len()
+		bc := sha256BatchChecksum(batch.Bytes(), offset, int64(len(rows)), keyStart, keyEnd)
+		if cf == nil {
+			if err := out.Write(ctx, &batch); err != nil {
+				return fmt.Errorf("failed to write batch: %v", err)
+			}
+		}
+		metrics.RecordBatch(db.config.Type, table, len(rows), time.Since(batchStart), batchBytes)
+		mb.addBatch(bc)
 
 		// Update state
-		if err := db.stateManager.UpdateState(table, processedRows); err != nil {
+		if err := db.stateManager.UpdateState(ctx, table, processedRows); err != nil {
 			return fmt.Errorf("failed to update state: %v", err)
 		}
+		if err := db.stateManager.RecordBatch(ctx, table, bc); err != nil {
+			return fmt.Errorf("failed to record batch checksum: %v", err)
+		}
+	}
+
+	if cf != nil {
+		data, err := cf.Bytes()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s output: %v", format, err)
+		}
+		if err := out.Write(ctx, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to write %s output: %v", format, err)
+		}
+	}
+
+	if err := out.Finalize(); err != nil {
+		return fmt.Errorf("failed to finalize output: %v", err)
+	}
+
+	if err := finalizeManifest(ctx, db, db.stateManager, table, keyColumns, whereClause, format, outputFile, []state.ManifestPart{mb.part()}); err != nil {
+		return fmt.Errorf("failed to verify extraction: %v", err)
 	}
 
 	return nil
 }
 
-func (db *DuckDB) GetTotalRows(table string) (int64, error) {
+func (db *DuckDB) GetTotalRows(ctx context.Context, table string) (int64, error) {
 	sqlDB, ok := db.db.(*sql.DB)
 	if !ok || sqlDB == nil {
 		return 0, fmt.Errorf("database connection not initialized")
@@ -127,21 +245,21 @@ func (db *DuckDB) GetTotalRows(table string) (int64, error) {
 
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
 	var count int64
-	err := sqlDB.QueryRow(query).Scan(&count)
+	err := sqlDB.QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get row count: %v", err)
 	}
 	return count, nil
 }
 
-func (db *DuckDB) GetColumns(table string) ([]string, error) {
+func (db *DuckDB) GetColumns(ctx context.Context, table string) ([]string, error) {
 	sqlDB, ok := db.db.(*sql.DB)
 	if !ok || sqlDB == nil {
 		return nil, fmt.Errorf("database connection not initialized")
 	}
 
 	query := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = '%s' ORDER BY ordinal_position", table)
-	rows, err := sqlDB.Query(query)
+	rows, err := sqlDB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %v", err)
 	}
@@ -158,23 +276,28 @@ func (db *DuckDB) GetColumns(table string) ([]string, error) {
 	return columns, nil
 }
 
-func (db *DuckDB) ExtractBatch(table string, offset, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error) {
+func (db *DuckDB) ExtractBatch(ctx context.Context, table string, offset, limit int64, keyColumns, whereClause, resumeMode string) ([]map[string]interface{}, error) {
+	ctx, cancel := withBatchTimeout(ctx, db.config)
+	defer cancel()
+
 	sqlDB, ok := db.db.(*sql.DB)
 	if !ok || sqlDB == nil {
 		return nil, fmt.Errorf("database connection not initialized")
 	}
 
-	// Build the query with WHERE clause and ORDER BY if key columns are provided
+	if useKeyset(resumeMode, keyColumns) {
+		return db.extractBatchKeyset(ctx, sqlDB, table, limit, keyColumns, whereClause)
+	}
+
+	// No ordered unique key was given, so fall back to plain LIMIT/OFFSET
+	// paging.
 	query := fmt.Sprintf("SELECT * FROM %s", table)
 	if whereClause != "" {
 		query += " WHERE " + whereClause
 	}
-	if keyColumns != "" {
-		query += " ORDER BY " + keyColumns
-	}
 	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 
-	rows, err := sqlDB.Query(query)
+	rows, err := sqlDB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %v", err)
 	}
@@ -207,7 +330,92 @@ func (db *DuckDB) ExtractBatch(table string, offset, limit int64, keyColumns, wh
 	return result, nil
 }
 
-func (db *DuckDB) GetTableSchema(tableName string) ([]Column, error) {
+// extractBatchKeyset replaces LIMIT/OFFSET with keyset (seek) pagination,
+// tracking the last key tuple emitted in state.State.LastKey so each batch
+// only scans forward from where the previous one stopped instead of
+// rescanning and discarding `offset` rows on every call.
+func (db *DuckDB) extractBatchKeyset(ctx context.Context, sqlDB *sql.DB, table string, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error) {
+	keys := strings.Split(keyColumns, ",")
+	for i, k := range keys {
+		keys[i] = strings.TrimSpace(k)
+	}
+
+	currentState, err := db.stateManager.GetState(ctx, table)
+	if err != nil {
+		currentState = nil
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s", table)
+	var args []interface{}
+
+	var conditions []string
+	if whereClause != "" {
+		conditions = append(conditions, whereClause)
+	}
+	if currentState != nil && len(currentState.LastKey) == len(keys) {
+		placeholders := make([]string, len(keys))
+		for i := range keys {
+			placeholders[i] = "?"
+			args = append(args, currentState.LastKey[i])
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", strings.Join(keys, ", "), strings.Join(placeholders, ", ")))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + strings.Join(keys, ", ")
+	query += fmt.Sprintf(" LIMIT %d", limit)
+
+	rows, err := sqlDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute keyset query: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %v", err)
+	}
+	keyIndex := make(map[string]int, len(keys))
+	for i, col := range columns {
+		keyIndex[col] = i
+	}
+
+	var result []map[string]interface{}
+	var lastValues []interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		lastValues = values
+
+		row := make(map[string]interface{})
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	if len(lastValues) > 0 {
+		lastKey := make([]interface{}, len(keys))
+		for i, k := range keys {
+			lastKey[i] = lastValues[keyIndex[k]]
+		}
+		if err := db.stateManager.UpdateLastKey(ctx, table, lastKey); err != nil {
+			return nil, fmt.Errorf("failed to persist last key: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (db *DuckDB) GetTableSchema(ctx context.Context, tableName string) ([]Column, error) {
 	sqlDB, ok := db.db.(*sql.DB)
 	if !ok || sqlDB == nil {
 		return nil, fmt.Errorf("database connection not initialized")
@@ -220,7 +428,7 @@ func (db *DuckDB) GetTableSchema(tableName string) ([]Column, error) {
 		ORDER BY ordinal_position
 	`)
 
-	rows, err := sqlDB.Query(query, tableName)
+	rows, err := sqlDB.QueryContext(ctx, query, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get table schema: %v", err)
 	}
@@ -239,6 +447,29 @@ func (db *DuckDB) GetTableSchema(tableName string) ([]Column, error) {
 	return columns, nil
 }
 
+// GetTableSchemaHash returns a content-addressed hash of tableName's
+// current columns, computed inside the query via md5(string_agg(...))
+// rather than pulling every column's name and type back to Go and
+// hashing there.
+func (db *DuckDB) GetTableSchemaHash(ctx context.Context, tableName string) (string, error) {
+	sqlDB, ok := db.db.(*sql.DB)
+	if !ok || sqlDB == nil {
+		return "", fmt.Errorf("database connection not initialized")
+	}
+
+	query := `
+		SELECT COALESCE(md5(string_agg(column_name || ':' || data_type, ',' ORDER BY column_name)), '')
+		FROM information_schema.columns
+		WHERE table_name = $1
+	`
+
+	var hash string
+	if err := sqlDB.QueryRowContext(ctx, query, tableName).Scan(&hash); err != nil {
+		return "", fmt.Errorf("failed to hash table schema: %v", err)
+	}
+	return hash, nil
+}
+
 func (db *DuckDB) GetRowCount(tableName string) (int64, error) {
 	sqlDB, ok := db.db.(*sql.DB)
 	if !ok || sqlDB == nil {
@@ -266,3 +497,70 @@ func (db *DuckDB) Exec(ctx context.Context, query string) error {
 	}
 	return nil
 }
+
+// ExtractArrow streams table into w using DuckDB's native Arrow interface
+// (duckdb.NewArrowFromConn), avoiding the database/sql row-boxing the
+// default adapter falls back to for every other driver.
+func (db *DuckDB) ExtractArrow(ctx context.Context, table string, w *pqarrow.FileWriter, batchSize int64) error {
+	sqlDB, ok := db.db.(*sql.DB)
+	if !ok || sqlDB == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %v", err)
+	}
+	defer conn.Close()
+
+	var reader array.RecordReader
+	if err := conn.Raw(func(driverConn interface{}) error {
+		arrowDB, err := duckdb.NewArrowFromConn(driverConn.(driver.Conn))
+		if err != nil {
+			return err
+		}
+		reader, err = arrowDB.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s", table))
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to open arrow stream: %v", err)
+	}
+	defer reader.Release()
+
+	for reader.Next() {
+		if err := w.WriteBuffered(reader.Record()); err != nil {
+			return fmt.Errorf("failed to write arrow record: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// SubmitAsync runs sql synchronously in a background goroutine via
+// defaultSubmitAsync; Poll reports on it from there. DuckDB is an in-process
+// engine with no server-side job to reattach to, so there's no native async
+// mode to call into here.
+func (db *DuckDB) SubmitAsync(ctx context.Context, query string) (Handle, error) {
+	sqlDB, ok := db.db.(*sql.DB)
+	if !ok || sqlDB == nil {
+		return "", fmt.Errorf("database connection not initialized")
+	}
+	return defaultSubmitAsync(func() ([]map[string]interface{}, error) {
+		rows, err := sqlDB.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %v", err)
+		}
+		defer rows.Close()
+		return scanSQLRows(rows)
+	}), nil
+}
+
+func (db *DuckDB) Poll(ctx context.Context, handle Handle) (Status, []map[string]interface{}, error) {
+	return defaultPoll(handle)
+}
+
+// ChecksumTable falls back to defaultChecksumTable. DuckDB's hash()
+// scalar function could compute an equivalent aggregate server-side, but
+// that isn't wired up here yet.
+func (db *DuckDB) ChecksumTable(ctx context.Context, table, keyColumns, whereClause string) (string, error) {
+	return defaultChecksumTable(ctx, db, table, keyColumns, whereClause)
+}
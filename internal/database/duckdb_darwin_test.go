@@ -23,20 +23,20 @@ func TestDuckDB_Connect(t *testing.T) {
 	config := &config.Config{
 		Database: dbPath,
 	}
-	stateManager := state.NewMemoryStateManager()
+	stateManager := state.NewMemoryManager()
 	db, err := NewDuckDB(config, stateManager)
 	if err != nil {
 		t.Fatalf("Failed to create DuckDB instance: %v", err)
 	}
 
 	// Test connecting to a non-existent database
-	err = db.Connect()
+	err = db.Connect(context.Background())
 	if err != nil {
 		t.Errorf("Connect() failed: %v", err)
 	}
 
 	// Clean up
-	db.Close()
+	db.Close(context.Background())
 }
 
 func TestDuckDB_ExtractData(t *testing.T) {
@@ -50,14 +50,14 @@ func TestDuckDB_ExtractData(t *testing.T) {
 	config := &config.Config{
 		Database: dbPath,
 	}
-	stateManager := state.NewMemoryStateManager()
+	stateManager := state.NewMemoryManager()
 	db, err := NewDuckDB(config, stateManager)
 	if err != nil {
 		t.Fatalf("Failed to create DuckDB instance: %v", err)
 	}
 
 	// Connect to the database
-	if err := db.Connect(); err != nil {
+	if err := db.Connect(context.Background()); err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
@@ -86,7 +86,7 @@ func TestDuckDB_ExtractData(t *testing.T) {
 
 	// Test CSV export
 	csvOutput := filepath.Join(outputDir, "test.csv")
-	err = db.ExtractData("test_table", csvOutput, "csv", 1000, "", "")
+	err = db.ExtractData(context.Background(), "test_table", csvOutput, "csv", 1000, "", "", "auto")
 	if err != nil {
 		t.Errorf("ExtractData(CSV) failed: %v", err)
 	}
@@ -98,7 +98,7 @@ func TestDuckDB_ExtractData(t *testing.T) {
 
 	// Test Parquet export
 	parquetOutput := filepath.Join(outputDir, "test.parquet")
-	err = db.ExtractData("test_table", parquetOutput, "parquet", 1000, "", "")
+	err = db.ExtractData(context.Background(), "test_table", parquetOutput, "parquet", 1000, "", "", "auto")
 	if err != nil {
 		t.Errorf("ExtractData(Parquet) failed: %v", err)
 	}
@@ -109,7 +109,7 @@ func TestDuckDB_ExtractData(t *testing.T) {
 	}
 
 	// Clean up
-	db.Close()
+	db.Close(context.Background())
 }
 
 func TestDuckDB_GetColumns(t *testing.T) {
@@ -121,14 +121,14 @@ func TestDuckDB_GetColumns(t *testing.T) {
 	config := &config.Config{
 		Database: dbPath,
 	}
-	stateManager := state.NewMemoryStateManager()
+	stateManager := state.NewMemoryManager()
 	db, err := NewDuckDB(config, stateManager)
 	if err != nil {
 		t.Fatalf("Failed to create DuckDB instance: %v", err)
 	}
 
 	// Connect to the database
-	if err := db.Connect(); err != nil {
+	if err := db.Connect(context.Background()); err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
@@ -145,7 +145,7 @@ func TestDuckDB_GetColumns(t *testing.T) {
 	}
 
 	// Get columns
-	columns, err := db.GetColumns("test_table")
+	columns, err := db.GetColumns(context.Background(), "test_table")
 	if err != nil {
 		t.Errorf("GetColumns() failed: %v", err)
 	}
@@ -163,7 +163,7 @@ func TestDuckDB_GetColumns(t *testing.T) {
 	}
 
 	// Clean up
-	db.Close()
+	db.Close(context.Background())
 }
 
 func TestDuckDB_StateManagement(t *testing.T) {
@@ -175,14 +175,14 @@ func TestDuckDB_StateManagement(t *testing.T) {
 	config := &config.Config{
 		Database: dbPath,
 	}
-	stateManager := state.NewMemoryStateManager()
+	stateManager := state.NewMemoryManager()
 	db, err := NewDuckDB(config, stateManager)
 	if err != nil {
 		t.Fatalf("Failed to create DuckDB instance: %v", err)
 	}
 
 	// Connect to the database
-	if err := db.Connect(); err != nil {
+	if err := db.Connect(context.Background()); err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
@@ -209,13 +209,13 @@ func TestDuckDB_StateManagement(t *testing.T) {
 
 	// Test state management during extraction
 	outputFile := filepath.Join(tempDir, "test.csv")
-	err = db.ExtractData("test_table", outputFile, "csv", 1000, "", "")
+	err = db.ExtractData(context.Background(), "test_table", outputFile, "csv", 1000, "", "", "auto")
 	if err != nil {
 		t.Errorf("ExtractData() failed: %v", err)
 	}
 
 	// Verify state was updated
-	state, err := stateManager.GetState("test_table")
+	state, err := stateManager.GetState(context.Background(), "test_table")
 	if err != nil {
 		t.Errorf("Failed to get state: %v", err)
 	}
@@ -229,5 +229,5 @@ func TestDuckDB_StateManagement(t *testing.T) {
 	}
 
 	// Clean up
-	db.Close()
+	db.Close(context.Background())
 }
@@ -6,36 +6,42 @@ package database
 import (
 	"context"
 	"fmt"
+
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
 )
 
-func (db *DuckDB) Connect() error {
+func (db *DuckDB) Connect(ctx context.Context) error {
 	return fmt.Errorf("DuckDB support is only available on macOS")
 }
 
-func (db *DuckDB) Close() error {
+func (db *DuckDB) Close(ctx context.Context) error {
 	return nil
 }
 
-func (db *DuckDB) ExtractData(table, outputFile, format string, batchSize int, keyColumns, whereClause string) error {
+func (db *DuckDB) ExtractData(ctx context.Context, table, outputFile, format string, batchSize int, keyColumns, whereClause, resumeMode string) error {
 	return fmt.Errorf("DuckDB support is only available on macOS")
 }
 
-func (db *DuckDB) GetTotalRows(table string) (int64, error) {
+func (db *DuckDB) GetTotalRows(ctx context.Context, table string) (int64, error) {
 	return 0, fmt.Errorf("DuckDB support is only available on macOS")
 }
 
-func (db *DuckDB) GetColumns(table string) ([]string, error) {
+func (db *DuckDB) GetColumns(ctx context.Context, table string) ([]string, error) {
 	return nil, fmt.Errorf("DuckDB support is only available on macOS")
 }
 
-func (db *DuckDB) ExtractBatch(table string, offset, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error) {
+func (db *DuckDB) ExtractBatch(ctx context.Context, table string, offset, limit int64, keyColumns, whereClause, resumeMode string) ([]map[string]interface{}, error) {
 	return nil, fmt.Errorf("DuckDB support is only available on macOS")
 }
 
-func (db *DuckDB) GetTableSchema(tableName string) ([]Column, error) {
+func (db *DuckDB) GetTableSchema(ctx context.Context, tableName string) ([]Column, error) {
 	return nil, fmt.Errorf("DuckDB support is only available on macOS")
 }
 
+func (db *DuckDB) GetTableSchemaHash(ctx context.Context, tableName string) (string, error) {
+	return "", fmt.Errorf("DuckDB support is only available on macOS")
+}
+
 func (db *DuckDB) GetRowCount(tableName string) (int64, error) {
 	return 0, fmt.Errorf("DuckDB support is only available on macOS")
 }
@@ -43,3 +49,19 @@ func (db *DuckDB) GetRowCount(tableName string) (int64, error) {
 func (db *DuckDB) Exec(ctx context.Context, query string) error {
 	return fmt.Errorf("DuckDB support is only available on macOS")
 }
+
+func (db *DuckDB) ExtractArrow(ctx context.Context, table string, w *pqarrow.FileWriter, batchSize int64) error {
+	return fmt.Errorf("DuckDB support is only available on macOS")
+}
+
+func (db *DuckDB) SubmitAsync(ctx context.Context, sql string) (Handle, error) {
+	return "", fmt.Errorf("DuckDB support is only available on macOS")
+}
+
+func (db *DuckDB) Poll(ctx context.Context, handle Handle) (Status, []map[string]interface{}, error) {
+	return StatusFailed, nil, fmt.Errorf("DuckDB support is only available on macOS")
+}
+
+func (db *DuckDB) ChecksumTable(ctx context.Context, table, keyColumns, whereClause string) (string, error) {
+	return "", fmt.Errorf("DuckDB support is only available on macOS")
+}
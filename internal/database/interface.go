@@ -4,27 +4,92 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"time"
+
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
 
 	"github.com/gerhard-ee/sqlextract/internal/config"
 	"github.com/gerhard-ee/sqlextract/internal/state"
 )
 
-// Database defines the interface for database operations
+// Database defines the interface for database operations. Every method
+// takes ctx as its first argument and must stop and return ctx.Err() once
+// it's been canceled or its deadline has passed, the same convention
+// state.Manager already follows - so a caller can bound a call with a
+// timeout, abort it on SIGINT, or attach a tracing span, all the way down
+// to the driver's underlying query.
 type Database interface {
 	// Connect establishes a connection to the database
-	Connect() error
+	Connect(ctx context.Context) error
 	// Close closes the database connection
-	Close() error
-	// ExtractData extracts data from a table and writes it to a file
-	ExtractData(table, outputFile, format string, batchSize int, keyColumns, whereClause string) error
+	Close(ctx context.Context) error
+	// ExtractData extracts data from a table and writes it to a file.
+	// resumeMode is one of "offset", "keyset", or "auto" (see ExtractBatch).
+	ExtractData(ctx context.Context, table, outputFile, format string, batchSize int, keyColumns, whereClause, resumeMode string) error
 	// GetTotalRows returns the total number of rows in a table
-	GetTotalRows(table string) (int64, error)
+	GetTotalRows(ctx context.Context, table string) (int64, error)
 	// GetColumns returns the column names for a table
-	GetColumns(table string) ([]string, error)
-	// ExtractBatch extracts a batch of rows from a table
-	ExtractBatch(table string, offset, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error)
+	GetColumns(ctx context.Context, table string) ([]string, error)
+	// ExtractBatch extracts a batch of rows from a table. resumeMode
+	// selects the pagination strategy: "keyset" always seeks from the
+	// last committed state.State.LastKey tuple (keyColumns must be set),
+	// "offset" always uses LIMIT/OFFSET, and "auto" (the default) picks
+	// keyset when keyColumns is non-empty and falls back to offset
+	// otherwise.
+	ExtractBatch(ctx context.Context, table string, offset, limit int64, keyColumns, whereClause, resumeMode string) ([]map[string]interface{}, error)
 	// Exec executes a SQL query
 	Exec(ctx context.Context, query string) error
+	// ExtractArrow streams a table directly into a Parquet writer as Arrow
+	// record batches, bypassing the map[string]interface{} row conversion
+	// used by ExtractBatch. Drivers with a native Arrow source (Snowflake
+	// ARROW_BATCHES, BigQuery Storage Read API) should use it; others fall
+	// back to defaultExtractArrow, which adapts ExtractBatch.
+	ExtractArrow(ctx context.Context, table string, w *pqarrow.FileWriter, batchSize int64) error
+	// SubmitAsync submits sql for asynchronous execution and returns a
+	// Handle identifying it. Callers should persist the handle (e.g. via
+	// state.Manager.UpdateQueryID) so a restarted process can Poll it
+	// instead of resubmitting a multi-hour query.
+	SubmitAsync(ctx context.Context, sql string) (Handle, error)
+	// Poll reports the status of a statement previously submitted via
+	// SubmitAsync, returning its rows once Status is StatusDone.
+	Poll(ctx context.Context, handle Handle) (Status, []map[string]interface{}, error)
+	// ChecksumTable computes a deterministic, row-order-independent
+	// checksum of table (optionally narrowed by whereClause), so a
+	// manifest written while extracting can later be compared against a
+	// freshly computed checksum to confirm the source hasn't changed and
+	// the extraction was complete. Drivers with a native per-row
+	// hash/aggregate push the computation down to the engine; others fall
+	// back to defaultChecksumTable, which pages through ExtractBatch.
+	ChecksumTable(ctx context.Context, table, keyColumns, whereClause string) (string, error)
+}
+
+// withBatchTimeout derives a child context bounded by cfg's
+// BatchTimeoutSeconds, if set, so a single ExtractBatch call can't run
+// longer than configured even when the caller's ctx (e.g. the CLI's
+// signal-derived root context) carries no deadline of its own. A zero or
+// unset BatchTimeoutSeconds leaves ctx untouched.
+func withBatchTimeout(ctx context.Context, cfg *config.Config) (context.Context, context.CancelFunc) {
+	if cfg == nil || cfg.BatchTimeoutSeconds <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(cfg.BatchTimeoutSeconds)*time.Second)
+}
+
+// useKeyset decides whether ExtractBatch should page with keyset (seek)
+// pagination or plain LIMIT/OFFSET, given the caller's resumeMode and
+// whether keyColumns was supplied. "keyset" and "offset" force their
+// namesake strategy; "auto" (and any other value, for callers that
+// haven't been updated to pass a mode) keeps the pre-existing behavior
+// of using keyset whenever keyColumns is set.
+func useKeyset(resumeMode, keyColumns string) bool {
+	switch resumeMode {
+	case "offset":
+		return false
+	case "keyset":
+		return true
+	default:
+		return keyColumns != ""
+	}
 }
 
 // NewDatabase creates a new database instance based on the type
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gerhard-ee/sqlextract/internal/sink"
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// finalizeManifest is called by ExtractData once its row-paginated write
+// loop has finished: it checksums table on the source, assembles a
+// state.Manifest from parts plus that checksum, persists it through
+// stateManager (so `sqlextract -verify` can look it up by table name),
+// and writes the same manifest as a <outputFile>.manifest.json sidecar
+// via the sink package, so it lands next to the output wherever that is.
+func finalizeManifest(ctx context.Context, db Database, stateManager state.Manager, table, keyColumns, whereClause, format, outputFile string, parts []state.ManifestPart) error {
+	sourceChecksum, err := db.ChecksumTable(ctx, table, keyColumns, whereClause)
+	if err != nil {
+		return fmt.Errorf("failed to checksum source table: %v", err)
+	}
+
+	manifest := &state.Manifest{
+		Table:          table,
+		KeyColumns:     keyColumns,
+		WhereClause:    whereClause,
+		Format:         format,
+		Parts:          parts,
+		SourceChecksum: sourceChecksum,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := stateManager.SaveManifest(ctx, table, manifest); err != nil {
+		return fmt.Errorf("failed to save manifest: %v", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	manifestSink, err := sink.New(outputFile + ".manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to open manifest sink: %v", err)
+	}
+	if err := manifestSink.Write(ctx, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return manifestSink.Finalize()
+}
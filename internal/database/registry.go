@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gerhard-ee/sqlextract/internal/config"
+	"github.com/gerhard-ee/sqlextract/internal/metrics"
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// poolStatsInterval is how often a pool opened through ConnectionRegistry
+// reports its sql.DB.Stats() to internal/metrics.
+const poolStatsInterval = 15 * time.Second
+
+// poolKey identifies a *sql.DB a ConnectionRegistry can share across
+// PostgresDB instances. lib/pq ties a connection to one dbname at dial
+// time - Postgres has no "USE other_database" - so Database is part of
+// the key, not something a shared pool can switch per query. What the
+// registry actually saves is the common multi-tenant-on-one-cluster case
+// of many PostgresDB/table extractions against the *same* database, which
+// would otherwise each open (and TLS-handshake) their own pool.
+type poolKey struct {
+	Host     string
+	Port     int
+	User     string
+	SSLMode  string
+	Database string
+}
+
+func (k poolKey) String() string {
+	return fmt.Sprintf("%s:%d/%s@%s(%s)", k.Host, k.Port, k.Database, k.User, k.SSLMode)
+}
+
+// ConnectionRegistry shares *sql.DB connection pools across PostgresDB
+// instances that dial the same (host, port, user, sslmode, database),
+// rather than every NewPostgresDBFromRegistry call opening its own -
+// analogous to how a sharded filer keeps one pool per backing volume
+// instead of one per request. Callers extracting many tables out of the
+// same database (or many databases that happen to share a key) hand the
+// same *ConnectionRegistry to each NewPostgresDBFromRegistry call.
+type ConnectionRegistry struct {
+	mu    sync.Mutex
+	pools map[poolKey]*sql.DB
+}
+
+// NewConnectionRegistry creates an empty ConnectionRegistry.
+func NewConnectionRegistry() *ConnectionRegistry {
+	return &ConnectionRegistry{pools: make(map[poolKey]*sql.DB)}
+}
+
+// sslMode returns cfg.SSLMode, defaulting to "disable" to match every
+// driver's previous hard-coded connection string.
+func sslMode(cfg *config.Config) string {
+	if cfg.SSLMode == "" {
+		return "disable"
+	}
+	return cfg.SSLMode
+}
+
+// getOrOpen returns the pool for cfg's (host, port, user, sslmode,
+// database), opening and registering one - with cfg's
+// MaxIdleConnections/MaxOpenConnections applied and a stats reporter
+// started - the first time that key is seen.
+func (r *ConnectionRegistry) getOrOpen(ctx context.Context, cfg *config.Config) (*sql.DB, poolKey, error) {
+	key := poolKey{Host: cfg.Host, Port: cfg.Port, User: cfg.User, SSLMode: sslMode(cfg), Database: cfg.Database}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pool, ok := r.pools[key]; ok {
+		return pool, key, nil
+	}
+
+	connStr := fmt.Sprintf(
+		"host=%s port=%d dbname=%s user=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Database, cfg.User, cfg.Password, key.SSLMode,
+	)
+	pool, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, key, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	if cfg.MaxIdleConnections > 0 {
+		pool.SetMaxIdleConns(cfg.MaxIdleConnections)
+	}
+	if cfg.MaxOpenConnections > 0 {
+		pool.SetMaxOpenConns(cfg.MaxOpenConnections)
+	}
+	if err := pool.PingContext(ctx); err != nil {
+		pool.Close()
+		return nil, key, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	r.pools[key] = pool
+	go r.reportStats(key, pool)
+	return pool, key, nil
+}
+
+// reportStats publishes pool's sql.DB.Stats() to internal/metrics every
+// poolStatsInterval for as long as the process runs - the same
+// process-lifetime lifecycle internal/metrics itself already uses for
+// its other gauges and counters.
+func (r *ConnectionRegistry) reportStats(key poolKey, pool *sql.DB) {
+	ticker := time.NewTicker(poolStatsInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		stats := pool.Stats()
+		metrics.RecordPoolStats(key.String(), stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount)
+	}
+}
+
+// NewPostgresDBFromRegistry returns a Database backed by reg's shared
+// pool for cfg's (host, port, user, sslmode, database), instead of
+// dialing a dedicated one the way NewPostgres does. schema and role, if
+// non-empty, are applied via SET search_path/SET ROLE on Connect, so
+// callers sharing one physical database across tenant schemas can still
+// route each PostgresDB to its own tenant without a separate pool - the
+// part of the multi-tenancy story a shared *sql.DB pool actually
+// supports, since Postgres can't switch dbname mid-connection.
+func NewPostgresDBFromRegistry(ctx context.Context, reg *ConnectionRegistry, cfg *config.Config, stateManager state.Manager, schema, role string) (Database, error) {
+	pool, _, err := reg.getOrOpen(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &PostgresDB{
+		db:           pool,
+		config:       cfg,
+		stateManager: stateManager,
+	}
+
+	if schema != "" {
+		if _, err := pool.ExecContext(ctx, fmt.Sprintf("SET search_path TO %s", schema)); err != nil {
+			return nil, fmt.Errorf("failed to set search_path: %v", err)
+		}
+	}
+	if role != "" {
+		if _, err := pool.ExecContext(ctx, fmt.Sprintf("SET ROLE %s", role)); err != nil {
+			return nil, fmt.Errorf("failed to set role: %v", err)
+		}
+	}
+
+	return db, nil
+}
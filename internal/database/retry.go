@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gerhard-ee/sqlextract/internal/config"
+)
+
+// connectWithRetry calls openFn (which should both open the connection and
+// ping it, the same pair every driver's Connect already did) until it
+// returns a nil error, ctx is done, or policy.MaxAttempts is used up,
+// sleeping an exponentially growing backoff between attempts:
+// min(MaxBackoff, InitialBackoff * 2^attempt), plus up to backoff/2 of
+// jitter when policy.Jitter is set, so a fleet of workers reconnecting at
+// once doesn't retry in lockstep. policy's zero value means "try once",
+// matching every driver's previous unconditional fail-on-first-error
+// behavior.
+func connectWithRetry(ctx context.Context, policy config.ConnectionPolicy, openFn func() error) error {
+	attempts := policy.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	initialBackoff := policy.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = openFn(); err == nil {
+			return nil
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		backoff := initialBackoff * time.Duration(int64(1)<<uint(attempt))
+		if backoff <= 0 || backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		if policy.Jitter {
+			backoff += time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+	return fmt.Errorf("failed to connect after %d attempt(s): %v", attempts, err)
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// csvNull is the literal placeholder ExtractData writes for a SQL NULL in
+// CSV output - the same marker PostgresIngester/MSSQLIngester's \copy/BULK
+// INSERT scripts expect via their NULL 'NULL' option (see internal/ingest),
+// so a file this package writes round-trips through those ingesters
+// unchanged.
+const csvNull = "NULL"
+
+// RowWriter serializes a batch of rows to an underlying io.Writer in one
+// row-oriented output format (as opposed to ColumnarFormatter, which needs
+// to see every row across the whole extraction before it can write a
+// footer). ExtractData builds a new RowWriter per batch rather than
+// keeping one across the whole extraction, since encoding/csv.Writer and
+// json.Encoder carry no state that needs to survive between batches.
+type RowWriter interface {
+	// WriteHeader writes a header line, if the format has one (CSV does;
+	// newline-delimited JSON doesn't and treats this as a no-op).
+	WriteHeader(cols []Column) error
+	// WriteRow writes one row, keyed by cols[i].Name, in cols' order.
+	WriteRow(cols []Column, row map[string]interface{}) error
+	// Close flushes any buffered output. It does not close w.
+	Close() error
+}
+
+// NewRowWriter returns the RowWriter for format ("csv" or "jsonl"), or nil
+// for a format (e.g. "parquet", "arrow") that needs a ColumnarFormatter
+// instead.
+func NewRowWriter(format string, w io.Writer) RowWriter {
+	switch format {
+	case "csv":
+		return &csvRowWriter{w: csv.NewWriter(w)}
+	case "jsonl":
+		return &jsonlRowWriter{enc: json.NewEncoder(w)}
+	default:
+		return nil
+	}
+}
+
+// csvRowWriter wraps encoding/csv so embedded commas, quotes, and newlines
+// in a column value are escaped per RFC 4180 instead of corrupting the
+// file - the naive fmt.Sprintf-and-join it replaces silently produced
+// unparseable output for exactly that data.
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (rw *csvRowWriter) WriteHeader(cols []Column) error {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return rw.w.Write(names)
+}
+
+func (rw *csvRowWriter) WriteRow(cols []Column, row map[string]interface{}) error {
+	values := make([]string, len(cols))
+	for i, c := range cols {
+		if v := row[c.Name]; v == nil {
+			values[i] = csvNull
+		} else {
+			values[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return rw.w.Write(values)
+}
+
+func (rw *csvRowWriter) Close() error {
+	rw.w.Flush()
+	return rw.w.Error()
+}
+
+// jsonlRowWriter writes one JSON object per line (newline-delimited JSON),
+// preserving a real JSON null for a SQL NULL instead of CSV's "NULL"
+// string marker.
+type jsonlRowWriter struct {
+	enc *json.Encoder
+}
+
+func (rw *jsonlRowWriter) WriteHeader(cols []Column) error { return nil }
+
+func (rw *jsonlRowWriter) WriteRow(cols []Column, row map[string]interface{}) error {
+	return rw.enc.Encode(row)
+}
+
+func (rw *jsonlRowWriter) Close() error { return nil }
+
+// columnSchema returns db's declared column types via SchemaDescriber, if
+// it implements that optional capability, or nil if it doesn't (e.g.
+// MSSQLDB, SnowflakeDB today). namedColumns then falls back to an empty
+// Type for every column, which both RowWriter's writers and
+// ColumnarFormatter treat as "no type hint available".
+func columnSchema(ctx context.Context, db Database, table string) []Column {
+	sd, ok := db.(SchemaDescriber)
+	if !ok {
+		return nil
+	}
+	cols, err := sd.GetTableSchema(ctx, table)
+	if err != nil {
+		return nil
+	}
+	return cols
+}
+
+// namedColumns builds ordered Columns from columns (GetColumns' ordered
+// names, authoritative for the order row maps get serialized in) and
+// declared (types keyed by name, from columnSchema when the driver
+// implements SchemaDescriber).
+func namedColumns(columns []string, declared []Column) []Column {
+	types := make(map[string]string, len(declared))
+	for _, c := range declared {
+		types[c.Name] = c.Type
+	}
+	cols := make([]Column, len(columns))
+	for i, name := range columns {
+		cols[i] = Column{Name: name, Type: types[name]}
+	}
+	return cols
+}
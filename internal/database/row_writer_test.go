@@ -0,0 +1,42 @@
+package database
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+// TestCSVRowWriter_Escaping guards against the naive fmt.Sprintf-and-join
+// CSV writer csvRowWriter replaced: a value containing a comma, a quote,
+// or a newline must round-trip through encoding/csv rather than
+// corrupting the row layout.
+func TestCSVRowWriter_Escaping(t *testing.T) {
+	cols := []Column{{Name: "id"}, {Name: "note"}}
+	row := map[string]interface{}{
+		"id":   1,
+		"note": "has, a comma, a \"quote\", and a\nnewline",
+	}
+
+	var buf bytes.Buffer
+	rw := NewRowWriter("csv", &buf)
+	if err := rw.WriteHeader(cols); err != nil {
+		t.Fatalf("WriteHeader failed: %v", err)
+	}
+	if err := rw.WriteRow(cols, row); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse written CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected a header row and one data row, got %d rows", len(records))
+	}
+	if got := records[1]; len(got) != 2 || got[0] != "1" || got[1] != row["note"] {
+		t.Fatalf("expected row [1 %q], got %q", row["note"], got)
+	}
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// SchemaHasher is implemented by drivers that can report a content-
+// addressed hash of a table's current column schema more cheaply than
+// calling SchemaDescriber.GetTableSchema and hashing the result - e.g. by
+// computing the digest inside the SQL query instead of round-tripping
+// every column name and type. checkSchemaDrift falls back to
+// defaultSchemaHash (which does call GetTableSchema) for a driver that
+// only implements SchemaDescriber.
+type SchemaHasher interface {
+	GetTableSchemaHash(ctx context.Context, table string) (string, error)
+}
+
+// defaultSchemaHash hashes db's SchemaDescriber output for table into a
+// hex-encoded SHA-256 digest, sorting columns by name first so reordering
+// alone - which no engine here treats as a real schema change - doesn't
+// register as drift.
+func defaultSchemaHash(ctx context.Context, db SchemaDescriber, table string) (string, error) {
+	cols, err := db.GetTableSchema(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	return hashColumns(cols), nil
+}
+
+func hashColumns(cols []Column) string {
+	sorted := append([]Column(nil), cols...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	data, _ := json.Marshal(sorted)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SchemaDriftError reports that a table's column schema has changed since
+// the last extraction that recorded a snapshot of it: Added and Removed
+// list whole columns, Changed lists columns present in both snapshots
+// under a different declared type.
+type SchemaDriftError struct {
+	Added   []Column
+	Removed []Column
+	Changed []Column
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("schema drift detected for table: %d column(s) added, %d removed, %d changed type", len(e.Added), len(e.Removed), len(e.Changed))
+}
+
+// diffSchema compares previous (the last run's recorded snapshot) against
+// current (this run's GetTableSchema output), returning nil if every
+// column name and type still matches, regardless of order.
+func diffSchema(previous []state.ColumnSnapshot, current []Column) *SchemaDriftError {
+	prevByName := make(map[string]string, len(previous))
+	for _, c := range previous {
+		prevByName[c.Name] = c.Type
+	}
+	curByName := make(map[string]bool, len(current))
+
+	var drift SchemaDriftError
+	for _, c := range current {
+		curByName[c.Name] = true
+		if prevType, existed := prevByName[c.Name]; !existed {
+			drift.Added = append(drift.Added, c)
+		} else if prevType != c.Type {
+			drift.Changed = append(drift.Changed, c)
+		}
+	}
+	for _, c := range previous {
+		if !curByName[c.Name] {
+			drift.Removed = append(drift.Removed, Column{Name: c.Name, Type: c.Type})
+		}
+	}
+
+	if len(drift.Added) == 0 && len(drift.Removed) == 0 && len(drift.Changed) == 0 {
+		return nil
+	}
+	return &drift
+}
+
+// checkSchemaDrift compares table's current schema against the snapshot
+// recorded on currentState by the previous run that extracted it. It
+// returns the drift (nil if none), the hash to persist for next time, and
+// the column snapshot to persist alongside it. For a driver that
+// implements neither SchemaDescriber nor SchemaHasher, all three return
+// values are zero and no error - schema drift just isn't tracked for it.
+//
+// The common case - schema unchanged since last run - costs one
+// GetTableSchemaHash call and nothing else; GetTableSchema (pulling every
+// column's name and type) only runs when the hash actually moved, so a
+// driver with a cheap native SchemaHasher avoids paying for the full
+// column list on every run just to confirm nothing drifted.
+func checkSchemaDrift(ctx context.Context, db Database, table string, currentState *state.State) (*SchemaDriftError, string, []state.ColumnSnapshot, error) {
+	sd, ok := db.(SchemaDescriber)
+	if !ok {
+		return nil, "", nil, nil
+	}
+
+	var hash string
+	var err error
+	if hasher, ok := db.(SchemaHasher); ok {
+		hash, err = hasher.GetTableSchemaHash(ctx, table)
+	} else {
+		hash, err = defaultSchemaHash(ctx, sd, table)
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if currentState != nil && currentState.SchemaHash == hash {
+		return nil, hash, currentState.SchemaColumns, nil
+	}
+
+	cols, err := sd.GetTableSchema(ctx, table)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	snapshot := make([]state.ColumnSnapshot, len(cols))
+	for i, c := range cols {
+		snapshot[i] = state.ColumnSnapshot{Name: c.Name, Type: c.Type}
+	}
+
+	if currentState == nil || currentState.SchemaHash == "" {
+		// First run (or a state backend that never recorded one): nothing
+		// to compare against yet.
+		return nil, hash, snapshot, nil
+	}
+
+	return diffSchema(currentState.SchemaColumns, cols), hash, snapshot, nil
+}
@@ -1,23 +1,52 @@
 package database
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+
 	"github.com/gerhard-ee/sqlextract/internal/config"
+	"github.com/gerhard-ee/sqlextract/internal/metrics"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
 	"github.com/gerhard-ee/sqlextract/internal/state"
-	_ "github.com/snowflakedb/gosnowflake"
+	"github.com/snowflakedb/gosnowflake"
+)
+
+// asyncPollInterval is the initial backoff between polls of an in-flight
+// asynchronous Snowflake query. It doubles (capped at asyncPollMaxInterval)
+// on every retry of an ErrQueryIsRunning-style response.
+const (
+	asyncPollInterval    = 500 * time.Millisecond
+	asyncPollMaxInterval = 10 * time.Second
 )
 
+// unloadMaxFileSizeBytes bounds the size of each part file a COPY INTO
+// unload produces, matching Snowflake's own default guidance for GET speed.
+const unloadMaxFileSizeBytes = 256 * 1024 * 1024
+
 type SnowflakeDB struct {
 	db           *sql.DB
 	config       *config.Config
 	stateManager state.Manager
+
+	// snapshotAt holds the timestamp ExtractData captured at the start of
+	// one extraction, in RFC3339Nano. While set, ExtractBatch reads table
+	// through Snowflake's AT(TIMESTAMP => ...) time travel instead of the
+	// table's live state, so batches paged minutes apart still see the
+	// table as it stood when extraction began. Empty outside of
+	// ExtractData.
+	snapshotAt string
 }
 
 func NewSnowflake(cfg *config.Config, stateManager state.Manager) (Database, error) {
@@ -44,7 +73,7 @@ func NewSnowflake(cfg *config.Config, stateManager state.Manager) (Database, err
 	}, nil
 }
 
-func (db *SnowflakeDB) Connect() error {
+func (db *SnowflakeDB) Connect(ctx context.Context) error {
 	connStr := fmt.Sprintf(
 		"%s:%s@%s/%s/%s?warehouse=%s",
 		db.config.User, db.config.Password,
@@ -52,110 +81,364 @@ func (db *SnowflakeDB) Connect() error {
 		db.config.Warehouse,
 	)
 
-	var err error
-	db.db, err = sql.Open("snowflake", connStr)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %v", err)
-	}
-
-	if err := db.db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %v", err)
-	}
+	return connectWithRetry(ctx, db.config.Connection, func() error {
+		conn, err := sql.Open("snowflake", connStr)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %v", err)
+		}
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to ping database: %v", err)
+		}
 
-	return nil
+		db.db = conn
+		return nil
+	})
 }
 
-func (db *SnowflakeDB) Close() error {
+func (db *SnowflakeDB) Close(ctx context.Context) error {
 	if db.db != nil {
 		return db.db.Close()
 	}
 	return nil
 }
 
-func (db *SnowflakeDB) ExtractData(table, outputFile, format string, batchSize int, keyColumns, whereClause string) error {
+func (db *SnowflakeDB) ExtractData(ctx context.Context, table, outputFile, format string, batchSize int, keyColumns, whereClause, resumeMode string) (err error) {
+	// An empty format falls back to Config.Format, then "csv", so a
+	// caller that builds Config programmatically without setting a
+	// per-call format still gets well-defined behavior.
+	if format == "" {
+		format = db.config.Format
+	}
+	if format == "" {
+		format = "csv"
+	}
+
+	if db.config.Unload {
+		return db.unloadViaStage(ctx, table, outputFile, format)
+	}
+
+	// Capture a fixed point in time for the whole extraction so batches
+	// paged minutes apart read table through AT(TIMESTAMP => ...) time
+	// travel instead of whatever's newly committed by the time each batch
+	// query runs - the Postgres/MSSQL drivers get the same consistency
+	// from a single long-lived repeatable-read/snapshot transaction, which
+	// Snowflake has no equivalent of, but time travel gives the same
+	// result. If CURRENT_TIMESTAMP can't be read, ExtractBatch just falls
+	// back to an ordinary (non-time-traveled) query.
+	if snapAt, tErr := db.currentTimestamp(ctx); tErr == nil {
+		db.snapshotAt = snapAt
+		defer func() { db.snapshotAt = "" }()
+	}
+
 	// Get current state
-	currentState, err := db.stateManager.GetState(table)
-	if err != nil {
+	currentState, err := db.stateManager.GetState(ctx, table)
+	if err != nil || currentState == nil {
 		// Create new state if it doesn't exist
 		currentState = &state.State{
 			Table:       table,
 			LastUpdated: time.Now(),
 			Status:      "running",
 		}
-		if err := db.stateManager.CreateState(currentState); err != nil {
+		if err := db.stateManager.CreateState(ctx, currentState); err != nil {
 			return fmt.Errorf("failed to create state: %v", err)
 		}
+	} else if currentState.Status == "completed" {
+		// A crash-safe checkpoint: a previous run already finished this
+		// extraction, so there's nothing left to resume.
+		return nil
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
-	}
+	// A restarted process lands here after a crash mid-extraction; mark
+	// the outcome so the next run (or an operator) can tell completed,
+	// failed, and still-in-progress apart. This uses context.Background()
+	// so the status still gets recorded even if ctx was what caused err.
+	defer func() {
+		if err != nil {
+			db.stateManager.UpdateStatus(context.Background(), table, "failed", err.Error())
+		} else {
+			db.stateManager.UpdateStatus(context.Background(), table, "completed", "")
+		}
+	}()
 
 	// Get total rows
-	totalRows, err := db.GetTotalRows(table)
+	totalRows, err := db.GetTotalRows(ctx, table)
 	if err != nil {
 		return fmt.Errorf("failed to get total rows: %v", err)
 	}
 
 	// Get columns
-	columns, err := db.GetColumns(table)
+	columns, err := db.GetColumns(ctx, table)
 	if err != nil {
 		return fmt.Errorf("failed to get columns: %v", err)
 	}
 
-	// Create output file
-	file, err := os.Create(outputFile)
+	// outputFile may be a local path or an s3://, gs://, abfs:// URI; sink
+	// picks the right implementation and streams each batch to it as a
+	// rolling upload instead of requiring local disk equal to table size.
+	out, err := sink.New(outputFile)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return fmt.Errorf("failed to open output sink: %v", err)
 	}
-	defer file.Close()
 
-	// Write header if CSV format
-	if format == "csv" {
-		if _, err := fmt.Fprintf(file, "%s\n", strings.Join(columns, ",")); err != nil {
+	// schemaColumns carries declared SQL types when db implements
+	// SchemaDescriber, so the header/row writer and ColumnarFormatter
+	// both get a proper type hint instead of sniffing values.
+	schemaColumns := namedColumns(columns, columnSchema(ctx, db, table))
+
+	var hdr bytes.Buffer
+	if hw := NewRowWriter(format, &hdr); hw != nil {
+		if err := hw.WriteHeader(schemaColumns); err != nil {
 			return fmt.Errorf("failed to write header: %v", err)
 		}
+		if err := hw.Close(); err != nil {
+			return fmt.Errorf("failed to write header: %v", err)
+		}
+		if hdr.Len() > 0 {
+			if err := out.Write(ctx, &hdr); err != nil {
+				return fmt.Errorf("failed to write header: %v", err)
+			}
+		}
 	}
 
-	// Process data in batches
+	// Parquet and Arrow IPC both end with a footer/EOS marker that has to
+	// see every row, so - unlike CSV - cf builds them up in memory across
+	// the whole extraction and they're flushed once after the loop,
+	// instead of being streamed to out batch by batch.
+	cf := NewColumnarFormatter(format, schemaColumns)
+
+	// Process data in batches. A restart after a crash resumes just past
+	// the last batch RecordBatch confirmed landed on disk, rather than at
+	// currentState.ProcessedRows, since a row offset can shift under a
+	// batch that's already been written if the source table is being
+	// written to concurrently.
 	processedRows := int64(0)
-	for offset := int64(0); offset < totalRows; offset += int64(batchSize) {
-		rows, err := db.ExtractBatch(table, offset, int64(batchSize), keyColumns, whereClause)
+	mb := newManifestBuilder(outputFile)
+	for _, bc := range currentState.Batches {
+		mb.addBatch(bc)
+		processedRows += bc.RowCount
+	}
+	for offset := resumeOffset(currentState.Batches); offset < totalRows; offset += int64(batchSize) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batchStart := time.Now()
+		rows, err := db.ExtractBatch(ctx, table, offset, int64(batchSize), keyColumns, whereClause, resumeMode)
 		if err != nil {
-			return fmt.Errorf("failed to extract batch: %v", err)
+			// The connection may have been dropped mid-extraction (a
+			// proxy reset, a failover); reconnect once and retry this
+			// same batch before giving up. The offset/keyset checkpoint
+			// above means the retry picks up exactly where we left off
+			// rather than restarting the extraction.
+			if connErr := db.Connect(ctx); connErr == nil {
+				rows, err = db.ExtractBatch(ctx, table, offset, int64(batchSize), keyColumns, whereClause, resumeMode)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to extract batch: %v", err)
+			}
 		}
 
 		// Write rows
-		for _, row := range rows {
-			if format == "csv" {
-				values := make([]string, len(columns))
-				for i, col := range columns {
-					if val := row[col]; val == nil {
-						values[i] = "NULL"
-					} else {
-						values[i] = fmt.Sprintf("%v", val)
-					}
-				}
-				if _, err := fmt.Fprintf(file, "%s\n", strings.Join(values, ",")); err != nil {
-					return fmt.Errorf("failed to write row: %v", err)
+		var batch bytes.Buffer
+		rw := NewRowWriter(format, &batch)
+		var keyStart, keyEnd []interface{}
+		for rowIdx, row := range rows {
+			if rw != nil {
+				if err := rw.WriteRow(schemaColumns, row); err != nil {
+					return fmt.Errorf("failed to encode row: %v", err)
 				}
 			}
+			key := rowKey(row, keyColumns)
+			if rowIdx == 0 {
+				keyStart = key
+			}
+			keyEnd = key
+			mb.addRow(row, key)
 			processedRows++
 		}
+		if rw != nil {
+			if err := rw.Close(); err != nil {
+				return fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		if cf != nil {
+			if err := cf.AddBatch(columns, rows); err != nil {
+				return fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		batchBytes := batch.Len()
+		bc := sha256BatchChecksum(batch.Bytes(), offset, int64(len(rows)), keyStart, keyEnd)
+		if cf == nil {
+			if err := out.Write(ctx, &batch); err != nil {
+				return fmt.Errorf("failed to write batch: %v", err)
+			}
+		}
+		metrics.RecordBatch(db.config.Type, table, len(rows), time.Since(batchStart), batchBytes)
+		mb.addBatch(bc)
 
 		// Update state
-		if err := db.stateManager.UpdateState(table, processedRows); err != nil {
+		if err := db.stateManager.UpdateState(ctx, table, processedRows); err != nil {
 			return fmt.Errorf("failed to update state: %v", err)
 		}
+		if err := db.stateManager.RecordBatch(ctx, table, bc); err != nil {
+			return fmt.Errorf("failed to record batch checksum: %v", err)
+		}
+	}
+
+	if cf != nil {
+		data, err := cf.Bytes()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s output: %v", format, err)
+		}
+		if err := out.Write(ctx, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to write %s output: %v", format, err)
+		}
+	}
+
+	if err := out.Finalize(); err != nil {
+		return fmt.Errorf("failed to finalize output: %v", err)
+	}
+
+	if err := finalizeManifest(ctx, db, db.stateManager, table, keyColumns, whereClause, format, outputFile, []state.ManifestPart{mb.part()}); err != nil {
+		return fmt.Errorf("failed to verify extraction: %v", err)
 	}
 
 	return nil
 }
 
-func (db *SnowflakeDB) ExtractBatch(table string, offset, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error) {
+// unloadViaStage bypasses row-by-row SELECT ... LIMIT/OFFSET pagination by
+// unloading table server-side with COPY INTO. When outputFile is itself an
+// s3://, gs://, or abfs:// URI, -unload-stage is expected to already name
+// an external stage bound to that same cloud location, so the unload can
+// write straight there and skip the client entirely. Otherwise it unloads
+// to an (internal) stage, then GET's the resulting part files to
+// outputFile's directory and concatenates them.
+func (db *SnowflakeDB) unloadViaStage(ctx context.Context, table, outputFile, format string) error {
+	if sink.IsRemote(outputFile) {
+		return db.unloadViaExternalStage(ctx, table, format)
+	}
+
+	outDir := filepath.Dir(outputFile)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	fileFormat := "CSV"
+	if format == "parquet" {
+		fileFormat = "PARQUET"
+	}
+
+	stagePath := fmt.Sprintf("%s/%s/", strings.TrimRight(db.config.UnloadStage, "/"), table)
+	copyQuery := fmt.Sprintf(
+		"COPY INTO %s FROM %s FILE_FORMAT=(TYPE=%s) MAX_FILE_SIZE=%d OVERWRITE=TRUE",
+		stagePath, table, fileFormat, unloadMaxFileSizeBytes,
+	)
+	if _, err := db.db.ExecContext(ctx, copyQuery); err != nil {
+		return fmt.Errorf("failed to unload %s via COPY INTO: %v", table, err)
+	}
+
+	getQuery := fmt.Sprintf("GET %s file://%s/", stagePath, outDir)
+	if _, err := db.db.ExecContext(ctx, getQuery); err != nil {
+		return fmt.Errorf("failed to GET unloaded files for %s: %v", table, err)
+	}
+
+	return concatenatePartFiles(outDir, table, outputFile)
+}
+
+// unloadViaExternalStage issues the same COPY INTO as unloadViaStage but
+// against the external stage named by -unload-stage, which must already
+// point at outputFile's cloud location (e.g. CREATE STAGE my_s3_stage
+// URL='s3://bucket/prefix'). The unloaded files land directly at
+// outputFile, so no GET or local concatenation step is needed.
+func (db *SnowflakeDB) unloadViaExternalStage(ctx context.Context, table, format string) error {
+	fileFormat := "CSV"
+	if format == "parquet" {
+		fileFormat = "PARQUET"
+	}
+
+	copyQuery := fmt.Sprintf(
+		"COPY INTO %s FROM %s FILE_FORMAT=(TYPE=%s) MAX_FILE_SIZE=%d OVERWRITE=TRUE",
+		db.config.UnloadStage, table, fileFormat, unloadMaxFileSizeBytes,
+	)
+	if _, err := db.db.ExecContext(ctx, copyQuery); err != nil {
+		return fmt.Errorf("failed to unload %s to external stage %s: %v", table, db.config.UnloadStage, err)
+	}
+
+	return nil
+}
+
+// concatenatePartFiles merges the part files GET'd from a Snowflake stage
+// (named data_0_0_0.csv.gz, data_0_1_0.csv.gz, ...) into a single
+// outputFile and removes the parts once merged.
+func concatenatePartFiles(dir, table, outputFile string) error {
+	parts, err := filepath.Glob(filepath.Join(dir, "data_*"))
+	if err != nil {
+		return fmt.Errorf("failed to glob unloaded part files: %v", err)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("no unloaded part files found for table %s in %s", table, dir)
+	}
+	sort.Strings(parts)
+
+	out, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create merged output file: %v", err)
+	}
+	defer out.Close()
+
+	for _, part := range parts {
+		if err := func() error {
+			f, err := os.Open(part)
+			if err != nil {
+				return fmt.Errorf("failed to open part file %s: %v", part, err)
+			}
+			defer f.Close()
+
+			if _, err := io.Copy(out, f); err != nil {
+				return fmt.Errorf("failed to append part file %s: %v", part, err)
+			}
+			return nil
+		}(); err != nil {
+			return err
+		}
+		if err := os.Remove(part); err != nil {
+			return fmt.Errorf("failed to remove part file %s: %v", part, err)
+		}
+	}
+
+	return nil
+}
+
+// currentTimestamp reads Snowflake's CURRENT_TIMESTAMP() and formats it as
+// RFC3339Nano, for ExtractData to pin a time-travel snapshot to.
+func (db *SnowflakeDB) currentTimestamp(ctx context.Context) (string, error) {
+	var ts time.Time
+	if err := db.db.QueryRowContext(ctx, "SELECT CURRENT_TIMESTAMP()").Scan(&ts); err != nil {
+		return "", fmt.Errorf("failed to read current timestamp: %v", err)
+	}
+	return ts.Format(time.RFC3339Nano), nil
+}
+
+// tableWithSnapshot appends Snowflake's AT(TIMESTAMP => ...) time-travel
+// clause when ExtractData has pinned db.snapshotAt, so every batch reads
+// table as of the moment extraction began rather than its live state.
+// Returns table unchanged outside of ExtractData (snapshotAt is unset).
+func (db *SnowflakeDB) tableWithSnapshot(table string) string {
+	if db.snapshotAt == "" {
+		return table
+	}
+	return fmt.Sprintf("%s AT(TIMESTAMP => '%s'::TIMESTAMP_TZ)", table, db.snapshotAt)
+}
+
+func (db *SnowflakeDB) ExtractBatch(ctx context.Context, table string, offset, limit int64, keyColumns, whereClause, resumeMode string) ([]map[string]interface{}, error) {
+	ctx, cancel := withBatchTimeout(ctx, db.config)
+	defer cancel()
+
+	if useKeyset(resumeMode, keyColumns) && !db.config.Async {
+		return db.extractBatchKeyset(ctx, table, limit, keyColumns, whereClause)
+	}
+
 	// Build query
-	query := fmt.Sprintf("SELECT * FROM %s", table)
+	query := fmt.Sprintf("SELECT * FROM %s", db.tableWithSnapshot(table))
 	if whereClause != "" {
 		query += " WHERE " + whereClause
 	}
@@ -164,12 +447,184 @@ func (db *SnowflakeDB) ExtractBatch(table string, offset, limit int64, keyColumn
 	}
 	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 
-	rows, err := db.db.Query(query)
+	if !db.config.Async {
+		rows, err := db.db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %v", err)
+		}
+		defer rows.Close()
+		return scanSnowflakeRows(rows)
+	}
+
+	return db.extractBatchAsync(ctx, table, query)
+}
+
+// extractBatchKeyset replaces LIMIT/OFFSET with keyset (seek) pagination,
+// tracking the last key tuple emitted in state.State.LastKey so each batch
+// only scans forward from where the previous one stopped instead of
+// rescanning and discarding `offset` rows.
+func (db *SnowflakeDB) extractBatchKeyset(ctx context.Context, table string, limit int64, keyColumns, whereClause string) ([]map[string]interface{}, error) {
+	keys := strings.Split(keyColumns, ",")
+	for i, k := range keys {
+		keys[i] = strings.TrimSpace(k)
+	}
+
+	currentState, err := db.stateManager.GetState(ctx, table)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %v", err)
+		currentState = nil
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s", db.tableWithSnapshot(table))
+	var args []interface{}
+
+	var conditions []string
+	if whereClause != "" {
+		conditions = append(conditions, whereClause)
+	}
+	if currentState != nil && len(currentState.LastKey) == len(keys) {
+		placeholders := make([]string, len(keys))
+		for i := range keys {
+			placeholders[i] = "?"
+			args = append(args, currentState.LastKey[i])
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", strings.Join(keys, ", "), strings.Join(placeholders, ", ")))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + strings.Join(keys, ", ")
+	query += fmt.Sprintf(" LIMIT %d", limit)
+
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute keyset query: %v", err)
 	}
 	defer rows.Close()
 
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %v", err)
+	}
+	keyIndex := make(map[string]int, len(keys))
+	for i, col := range columns {
+		keyIndex[col] = i
+	}
+
+	var result []map[string]interface{}
+	var lastValues []interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+
+		row := make(map[string]interface{})
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+		lastValues = values
+	}
+
+	if len(lastValues) > 0 {
+		lastKey := make([]interface{}, len(keys))
+		for i, k := range keys {
+			lastKey[i] = lastValues[keyIndex[k]]
+		}
+		if err := db.stateManager.UpdateLastKey(ctx, table, lastKey); err != nil {
+			return nil, fmt.Errorf("failed to persist last key: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// extractBatchAsync runs query in Snowflake's asynchronous mode. If a
+// pending query ID is already recorded for the table (e.g. from a process
+// that crashed mid-extraction), it re-attaches to that query's results via
+// RESULT_SCAN instead of re-issuing query and burning warehouse credits.
+func (db *SnowflakeDB) extractBatchAsync(ctx context.Context, table, query string) ([]map[string]interface{}, error) {
+	// queryIDChan, if set, is how gosnowflake hands back the query ID for
+	// a freshly submitted async query; re-attaching via RESULT_SCAN
+	// already knows its query ID, so it's left nil.
+	var queryIDChan chan string
+	if currentState, err := db.stateManager.GetState(ctx, table); err == nil && currentState != nil && currentState.QueryID != "" {
+		query = fmt.Sprintf("SELECT * FROM TABLE(RESULT_SCAN('%s'))", currentState.QueryID)
+	} else {
+		queryIDChan = make(chan string, 1)
+		ctx = gosnowflake.WithQueryIDChan(gosnowflake.WithAsyncMode(ctx), queryIDChan)
+	}
+
+	rows, err := db.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute async query: %v", err)
+	}
+	defer rows.Close()
+
+	if queryIDChan != nil {
+		select {
+		case queryID := <-queryIDChan:
+			if err := db.stateManager.UpdateQueryID(ctx, table, queryID); err != nil {
+				return nil, fmt.Errorf("failed to persist query id: %v", err)
+			}
+		default:
+		}
+	}
+
+	result, err := pollSnowflakeRows(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query has fully drained, clear the pending query ID so a restart
+	// doesn't try to re-attach to an already-consumed result set.
+	if err := db.stateManager.UpdateQueryID(ctx, table, ""); err != nil {
+		return nil, fmt.Errorf("failed to clear query id: %v", err)
+	}
+
+	return result, nil
+}
+
+// pollSnowflakeRows scans rows, retrying with exponential backoff when the
+// driver reports that the async query is still running.
+func pollSnowflakeRows(ctx context.Context, rows *sql.Rows) ([]map[string]interface{}, error) {
+	backoff := asyncPollInterval
+	for {
+		result, err := scanSnowflakeRows(rows)
+		if err == nil {
+			return result, nil
+		}
+		if !isQueryStillRunning(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > asyncPollMaxInterval {
+			backoff = asyncPollMaxInterval
+		}
+	}
+}
+
+// isQueryStillRunning reports whether err indicates that an asynchronous
+// Snowflake query has not finished executing yet and should be retried.
+func isQueryStillRunning(err error) bool {
+	var sfErr *gosnowflake.SnowflakeError
+	if errors.As(err, &sfErr) && sfErr.Number == gosnowflake.ErrQueryIsRunning {
+		return true
+	}
+	return strings.Contains(err.Error(), "is still running")
+}
+
+func scanSnowflakeRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %v", err)
@@ -197,11 +652,11 @@ func (db *SnowflakeDB) ExtractBatch(table string, offset, limit int64, keyColumn
 	return result, nil
 }
 
-func (db *SnowflakeDB) GetTotalRows(table string) (int64, error) {
+func (db *SnowflakeDB) GetTotalRows(ctx context.Context, table string) (int64, error) {
 	// Try to get an exact count first
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
 	var count int64
-	err := db.db.QueryRow(query).Scan(&count)
+	err := db.db.QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total rows: %v", err)
 	}
@@ -213,7 +668,7 @@ func (db *SnowflakeDB) GetTotalRows(table string) (int64, error) {
 			FROM TABLE_INFORMATION
 			WHERE TABLE_NAME = $1
 		`)
-		err = db.db.QueryRow(query, table).Scan(&count)
+		err = db.db.QueryRowContext(ctx, query, table).Scan(&count)
 		if err != nil {
 			return 0, fmt.Errorf("failed to get approximate row count: %v", err)
 		}
@@ -222,9 +677,9 @@ func (db *SnowflakeDB) GetTotalRows(table string) (int64, error) {
 	return count, nil
 }
 
-func (db *SnowflakeDB) GetColumns(table string) ([]string, error) {
+func (db *SnowflakeDB) GetColumns(ctx context.Context, table string) ([]string, error) {
 	query := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = '%s' ORDER BY ordinal_position", table)
-	rows, err := db.db.Query(query)
+	rows, err := db.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %v", err)
 	}
@@ -272,6 +727,65 @@ func (db *SnowflakeDB) getPrimaryKeyColumns(table string) ([]string, error) {
 	return columns, nil
 }
 
+// ExtractArrow streams table into w using gosnowflake's ARROW_BATCHES
+// result format, which hands back native arrow.Record values directly from
+// the driver instead of converting rows through map[string]interface{}
+// like ExtractBatch does. This preserves typed decimals/timestamps and
+// avoids re-encoding Arrow from stringified values.
+func (db *SnowflakeDB) ExtractArrow(ctx context.Context, table string, w *pqarrow.FileWriter, batchSize int64) error {
+	query := fmt.Sprintf("SELECT * FROM %s", table)
+
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %v", err)
+	}
+	defer conn.Close()
+
+	// conn.Raw hands back the driver's own driver.Conn/driver.Rows, whose
+	// dynamic type implements gosnowflake.SnowflakeRows - unlike the
+	// *sql.Rows database/sql.Conn.QueryContext returns, which doesn't.
+	var batches []*gosnowflake.ArrowBatch
+	if err := conn.Raw(func(driverConn interface{}) error {
+		queryer, ok := driverConn.(driver.QueryerContext)
+		if !ok {
+			return fmt.Errorf("driver connection does not support QueryerContext")
+		}
+		rows, err := queryer.QueryContext(gosnowflake.WithArrowBatches(ctx), query, nil)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %v", err)
+		}
+		defer rows.Close()
+
+		sfRows, ok := rows.(gosnowflake.SnowflakeRows)
+		if !ok {
+			return fmt.Errorf("driver did not return a Snowflake result set")
+		}
+
+		b, err := sfRows.GetArrowBatches()
+		if err != nil {
+			return fmt.Errorf("failed to get arrow batches: %v", err)
+		}
+		batches = b
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		records, err := batch.Fetch()
+		if err != nil {
+			return fmt.Errorf("failed to fetch arrow batch: %v", err)
+		}
+		for _, record := range *records {
+			if err := w.WriteBuffered(record); err != nil {
+				return fmt.Errorf("failed to write arrow record: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (db *SnowflakeDB) Exec(ctx context.Context, query string) error {
 	_, err := db.db.ExecContext(ctx, query)
 	if err != nil {
@@ -279,3 +793,68 @@ func (db *SnowflakeDB) Exec(ctx context.Context, query string) error {
 	}
 	return nil
 }
+
+// SubmitAsync submits sql using Snowflake's native asynchronous query mode
+// and returns its query ID as the Handle. A later, possibly restarted,
+// process can Poll the same Handle and reattach via RESULT_SCAN instead of
+// resubmitting the query - the same mechanism extractBatchAsync uses
+// internally, exposed here without the blocking wait.
+func (db *SnowflakeDB) SubmitAsync(ctx context.Context, sql string) (Handle, error) {
+	queryIDChan := make(chan string, 1)
+	ctx = gosnowflake.WithQueryIDChan(gosnowflake.WithAsyncMode(ctx), queryIDChan)
+
+	rows, err := db.db.QueryContext(ctx, sql)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit async query: %v", err)
+	}
+	defer rows.Close()
+
+	select {
+	case queryID := <-queryIDChan:
+		return Handle(queryID), nil
+	default:
+		return "", fmt.Errorf("driver did not report a Snowflake query ID")
+	}
+}
+
+// Poll checks on a query previously submitted via SubmitAsync by
+// re-querying its result set with RESULT_SCAN. It never blocks: a query
+// still running is reported as StatusRunning rather than waited on.
+func (db *SnowflakeDB) Poll(ctx context.Context, handle Handle) (Status, []map[string]interface{}, error) {
+	query := fmt.Sprintf("SELECT * FROM TABLE(RESULT_SCAN('%s'))", string(handle))
+	rows, err := db.db.QueryContext(ctx, query)
+	if err != nil {
+		if isQueryStillRunning(err) {
+			return StatusRunning, nil, nil
+		}
+		return StatusFailed, nil, fmt.Errorf("failed to poll async query: %v", err)
+	}
+	defer rows.Close()
+
+	result, err := scanSnowflakeRows(rows)
+	if err != nil {
+		if isQueryStillRunning(err) {
+			return StatusRunning, nil, nil
+		}
+		return StatusFailed, nil, err
+	}
+	return StatusDone, result, nil
+}
+
+// ChecksumTable computes a table checksum using Snowflake's native
+// BIT_XOR(HASH(*)) aggregate, so verifying an extraction doesn't require
+// reading every row back through ExtractBatch.
+func (db *SnowflakeDB) ChecksumTable(ctx context.Context, table, keyColumns, whereClause string) (string, error) {
+	query := fmt.Sprintf("SELECT COUNT(*), BIT_XOR(HASH(*)) FROM %s", table)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	var rowCount int64
+	var checksum int64
+	if err := db.db.QueryRow(query).Scan(&rowCount, &checksum); err != nil {
+		return "", fmt.Errorf("failed to checksum table: %v", err)
+	}
+
+	return fmt.Sprintf("%d:%x", rowCount, checksum), nil
+}
@@ -0,0 +1,665 @@
+// Package extractor coordinates parallel, shard-based extraction of a
+// single table. A table is split into a fixed number of shards up front;
+// each shard is extracted into its own "<outputPrefix>.shardN.<format>"
+// file by a worker pool. Progress is tracked through a state.Manager row
+// keyed "<jobID>#shardN", locked the same leader-election-lease way
+// state.PostgresManager and state.RedisManager already lock jobs - so
+// multiple sqlextract processes started against the same jobID and table
+// (on different hosts, pointed at a shared Postgres/Redis state backend)
+// cooperatively drain it instead of duplicating work. A worker that runs
+// out of shards to claim steals one whose lease has lapsed or whose
+// previous owner marked it "failed", so a crashed worker's shard still
+// gets finished by someone.
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerhard-ee/sqlextract/internal/checkpoint"
+	"github.com/gerhard-ee/sqlextract/internal/database"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// Strategy selects how Coordinator splits a table into shards.
+type Strategy string
+
+const (
+	// StrategyRange splits [MIN(key), MAX(key)] into Coordinator.parallelism
+	// equal-width numeric ranges. Requires a single numeric key column.
+	StrategyRange Strategy = "range"
+	// StrategyHash assigns row r to shard hash(key(r)) % parallelism, for
+	// composite or non-numeric keys a range split can't handle.
+	StrategyHash Strategy = "hash"
+	// StrategyNtile splits by row count (SQL NTILE) rather than key
+	// value, so shards carry an even number of rows even when a range
+	// split would leave them lopsided because the key isn't uniformly
+	// distributed. Requires a single key column.
+	StrategyNtile Strategy = "ntile"
+)
+
+// Shard is one slice of a table, scoped by a SQL predicate that Extract
+// ANDs onto the caller's whereClause.
+type Shard struct {
+	Index int
+	Where string
+}
+
+// Coordinator splits a table into shards and extracts them with a
+// worker pool, using a state.Manager both to checkpoint each shard's
+// progress and to arbitrate ownership of it between concurrent workers
+// and processes.
+type Coordinator struct {
+	db           database.Database
+	stateManager state.Manager
+	dbType       string
+	jobID        string
+	parallelism  int
+	strategy     Strategy
+	leaseTTL     time.Duration
+
+	// checkpointStore, when set via SetCheckpointStore, gets a
+	// checkpoint.Chunk record per shard per batch - finer-grained than
+	// stateManager's per-shard status, for operators who want to
+	// inspect or selectively wipe a single bad chunk (see the
+	// checkpoint package and the checkpoint-dump/-restore/-error-destroy
+	// subcommands). Extraction works the same with or without one set.
+	checkpointStore checkpoint.Store
+}
+
+// New creates a Coordinator. jobID identifies this extraction across
+// workers, processes, and restarts - shard state rows are keyed
+// "<jobID>#shard<N>", so two different jobIDs against the same table
+// never collide, and cooperating processes must agree on jobID (as well
+// as table, parallelism, and strategy, which together determine how the
+// shards are planned) to end up working the same shard set. dbType
+// selects the SQL hash expression StrategyHash uses.
+func New(db database.Database, stateManager state.Manager, dbType, jobID string, parallelism int, strategy Strategy) *Coordinator {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	return &Coordinator{
+		db:           db,
+		stateManager: stateManager,
+		dbType:       dbType,
+		jobID:        jobID,
+		parallelism:  parallelism,
+		strategy:     strategy,
+		leaseTTL:     2 * time.Minute,
+	}
+}
+
+// SetCheckpointStore attaches a checkpoint.Store to c, so each shard's
+// batches also get recorded as fine-grained checkpoint.Chunk records
+// (see the checkpoint package) alongside c's existing stateManager
+// bookkeeping. Extraction proceeds normally if this is never called.
+func (c *Coordinator) SetCheckpointStore(store checkpoint.Store) {
+	c.checkpointStore = store
+}
+
+// shardStateKey returns the state.Manager row key for shard i of c's job.
+func (c *Coordinator) shardStateKey(i int) string {
+	return fmt.Sprintf("%s#shard%d", c.jobID, i)
+}
+
+// shardResult pairs a finished shard's manifest entry with its index, so
+// Extract can put results from its out-of-order results channel back in
+// shard order before writing the final manifest.
+type shardResult struct {
+	index int
+	part  state.ManifestPart
+}
+
+// Extract plans c.parallelism shards of table (scoped by whereClause)
+// using c.strategy, then runs c.parallelism workers that claim and
+// extract them until none are left claimable. Each shard's rows are
+// written, as format, to "<outputPrefix>.shard<N>.<format>". Once every
+// shard finishes, a state.Manifest covering all of them is written to
+// "<outputPrefix>.manifest.json" and saved through stateManager, the same
+// way database.ExtractData's finalizeManifest does for a single-process
+// extraction, so `sqlextract -verify` works the same regardless of
+// -parallelism.
+func (c *Coordinator) Extract(ctx context.Context, table, outputPrefix, format, keyColumns, whereClause string, batchSize int) error {
+	keys := splitAndTrim(keyColumns)
+	shards, err := c.planShards(ctx, table, keys, whereClause)
+	if err != nil {
+		return fmt.Errorf("failed to plan shards: %v", err)
+	}
+
+	for _, shard := range shards {
+		key := c.shardStateKey(shard.Index)
+		if existing, err := c.stateManager.GetState(ctx, key); err != nil || existing == nil {
+			if err := c.stateManager.CreateState(ctx, &state.State{Table: key, Status: "running", LastUpdated: time.Now()}); err != nil {
+				return fmt.Errorf("failed to create state for shard %d: %v", shard.Index, err)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, c.parallelism)
+	resultsCh := make(chan shardResult, len(shards))
+	for w := 0; w < c.parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.runWorker(ctx, shards, table, outputPrefix, format, whereClause, batchSize, resultsCh); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	close(resultsCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+
+	results := make([]shardResult, 0, len(shards))
+	for result := range resultsCh {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].index < results[j].index })
+	parts := make([]state.ManifestPart, len(results))
+	for i, result := range results {
+		parts[i] = result.part
+	}
+
+	return c.writeManifest(ctx, table, outputPrefix, format, keyColumns, whereClause, parts)
+}
+
+// runWorker repeatedly claims and extracts a shard until claimShard finds
+// nothing left to do, sending each finished shard's manifest entry on
+// results. Because claimShard's locking is shared with every other
+// worker in this process and, through state.Manager, every other
+// cooperating process, a worker that finishes its own shard early goes
+// straight on to claim whatever's next instead of sitting idle - the
+// work-stealing behavior described at the package level.
+func (c *Coordinator) runWorker(ctx context.Context, shards []Shard, table, outputPrefix, format, whereClause string, batchSize int, results chan<- shardResult) error {
+	for {
+		shard, ok, err := c.claimShard(ctx, shards)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		key := c.shardStateKey(shard.Index)
+		part, err := c.extractShard(ctx, table, outputPrefix, format, whereClause, batchSize, shard)
+		if err != nil {
+			c.stateManager.UpdateStatus(ctx, key, "failed", err.Error())
+			// Release the lease immediately rather than waiting for it to
+			// lapse, so another worker can steal this shard right away.
+			c.stateManager.UnlockState(ctx, key)
+			return fmt.Errorf("failed to extract shard %d: %v", shard.Index, err)
+		}
+		if err := c.stateManager.UpdateStatus(ctx, key, "completed", ""); err != nil {
+			return fmt.Errorf("failed to mark shard %d completed: %v", shard.Index, err)
+		}
+		results <- shardResult{index: shard.Index, part: part}
+	}
+}
+
+// claimShard scans every shard via state.Manager.ListStates and tries to
+// lock the first one that isn't already "completed". LockState's
+// leader-election-lease semantics (see state.PostgresManager,
+// state.RedisManager) make this double as both the initial work
+// assignment - an unstarted shard has no lock yet - and the work-stealing
+// step: a shard whose owner's lease expired, or who marked it "failed"
+// and released the lease early, becomes lockable again for whoever asks
+// next.
+func (c *Coordinator) claimShard(ctx context.Context, shards []Shard) (Shard, bool, error) {
+	states, err := c.stateManager.ListStates(ctx)
+	if err != nil {
+		return Shard{}, false, fmt.Errorf("failed to list shard states: %v", err)
+	}
+	byKey := make(map[string]*state.State, len(states))
+	for _, s := range states {
+		byKey[s.Table] = s
+	}
+
+	for _, shard := range shards {
+		key := c.shardStateKey(shard.Index)
+		if s, ok := byKey[key]; ok && s.Status == "completed" {
+			continue
+		}
+		ok, err := c.stateManager.LockState(ctx, key, c.leaseTTL)
+		if err != nil {
+			return Shard{}, false, fmt.Errorf("failed to lock shard %d: %v", shard.Index, err)
+		}
+		if ok {
+			return shard, true, nil
+		}
+	}
+	return Shard{}, false, nil
+}
+
+// extractShard pages through every row table's whereClause AND
+// shard.Where matches, via plain offset pagination (ExtractBatch's
+// resumeMode "offset" - not its table-keyed keyset/LastKey machinery,
+// which assumes one resumable cursor per table and would collide across
+// shards extracting the same table concurrently). A shard reclaimed from
+// a dead or failed owner always restarts at offset 0 and overwrites its
+// output file: the previous owner's partial output can't be trusted
+// without also knowing exactly how many rows of it were actually flushed
+// to the sink before it died.
+func (c *Coordinator) extractShard(ctx context.Context, table, outputPrefix, format, whereClause string, batchSize int, shard Shard) (part state.ManifestPart, err error) {
+	key := c.shardStateKey(shard.Index)
+	chunkID := fmt.Sprintf("shard%d", shard.Index)
+
+	renewCtx, stopRenew := context.WithCancel(ctx)
+	defer stopRenew()
+	go c.renewLease(renewCtx, key)
+
+	var processedRows, byteOffset int64
+	sum := sha256.New()
+	defer func() {
+		if err != nil {
+			c.saveChunkCheckpoint(ctx, table, chunkID, checkpoint.StatusFailed, processedRows, byteOffset, sum, err.Error())
+		}
+	}()
+
+	c.saveChunkCheckpoint(ctx, table, chunkID, checkpoint.StatusLoading, 0, 0, sum, "")
+
+	shardWhere := shard.Where
+	if whereClause != "" {
+		shardWhere = whereClause + " AND " + shardWhere
+	}
+
+	columns, err := c.db.GetColumns(ctx, table)
+	if err != nil {
+		return part, fmt.Errorf("failed to get columns: %v", err)
+	}
+
+	// schemaColumns carries declared SQL types when c.db implements
+	// database.SchemaDescriber, so the row writer and columnar formatter
+	// below both get a proper type hint instead of sniffing values - the
+	// same pattern database.ExtractData follows for a single-process
+	// extraction.
+	var declared []database.Column
+	if sd, ok := c.db.(database.SchemaDescriber); ok {
+		if cols, err := sd.GetTableSchema(ctx, table); err == nil {
+			declared = cols
+		}
+	}
+	declaredType := make(map[string]string, len(declared))
+	for _, col := range declared {
+		declaredType[col.Name] = col.Type
+	}
+	schemaColumns := make([]database.Column, len(columns))
+	for i, name := range columns {
+		schemaColumns[i] = database.Column{Name: name, Type: declaredType[name]}
+	}
+
+	outputFile := fmt.Sprintf("%s.shard%d.%s", outputPrefix, shard.Index, format)
+	out, err := sink.New(outputFile)
+	if err != nil {
+		return part, fmt.Errorf("failed to open output sink: %v", err)
+	}
+
+	var hdr bytes.Buffer
+	if hw := database.NewRowWriter(format, &hdr); hw != nil {
+		if err := hw.WriteHeader(schemaColumns); err != nil {
+			return part, fmt.Errorf("failed to write header: %v", err)
+		}
+		if err := hw.Close(); err != nil {
+			return part, fmt.Errorf("failed to write header: %v", err)
+		}
+		if hdr.Len() > 0 {
+			if err := out.Write(ctx, &hdr); err != nil {
+				return part, fmt.Errorf("failed to write header: %v", err)
+			}
+		}
+	}
+
+	// Parquet and Arrow IPC both end with a footer/EOS marker that has to
+	// see every row, so - unlike CSV - cf builds the file up in memory
+	// across the whole shard and it's flushed once after the loop, instead
+	// of being streamed to out batch by batch.
+	cf := database.NewColumnarFormatter(format, schemaColumns)
+
+	c.saveChunkCheckpoint(ctx, table, chunkID, checkpoint.StatusLoaded, 0, 0, sum, "")
+
+	for offset := int64(0); ; offset += int64(batchSize) {
+		if err := ctx.Err(); err != nil {
+			return part, err
+		}
+		rows, err := c.db.ExtractBatch(ctx, table, offset, int64(batchSize), "", shardWhere, "offset")
+		if err != nil {
+			return part, fmt.Errorf("failed to extract batch at offset %d: %v", offset, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		var batch bytes.Buffer
+		rw := database.NewRowWriter(format, &batch)
+		for _, row := range rows {
+			if rw != nil {
+				if err := rw.WriteRow(schemaColumns, row); err != nil {
+					return part, fmt.Errorf("failed to encode row: %v", err)
+				}
+			}
+			processedRows++
+		}
+		if rw != nil {
+			if err := rw.Close(); err != nil {
+				return part, fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		if cf != nil {
+			if err := cf.AddBatch(columns, rows); err != nil {
+				return part, fmt.Errorf("failed to encode batch: %v", err)
+			}
+		}
+		sum.Write(batch.Bytes())
+		byteOffset += int64(batch.Len())
+		if cf == nil {
+			if err := out.Write(ctx, &batch); err != nil {
+				return part, fmt.Errorf("failed to write batch: %v", err)
+			}
+		}
+		if err := c.stateManager.UpdateState(ctx, key, processedRows); err != nil {
+			return part, fmt.Errorf("failed to update shard state: %v", err)
+		}
+		c.saveChunkCheckpoint(ctx, table, chunkID, checkpoint.StatusWritten, processedRows, byteOffset, sum, "")
+
+		if len(rows) < batchSize {
+			break
+		}
+	}
+
+	if cf != nil {
+		data, err := cf.Bytes()
+		if err != nil {
+			return part, fmt.Errorf("failed to encode %s output: %v", format, err)
+		}
+		sum.Write(data)
+		byteOffset += int64(len(data))
+		if err := out.Write(ctx, bytes.NewReader(data)); err != nil {
+			return part, fmt.Errorf("failed to write %s output: %v", format, err)
+		}
+	}
+
+	if err = out.Finalize(); err != nil {
+		return part, err
+	}
+	c.saveChunkCheckpoint(ctx, table, chunkID, checkpoint.StatusFinished, processedRows, byteOffset, sum, "")
+
+	part = state.ManifestPart{
+		Path:     outputFile,
+		RowCount: processedRows,
+		Checksum: fmt.Sprintf("%d:%s", processedRows, hex.EncodeToString(sum.Sum(nil))),
+	}
+	return part, nil
+}
+
+// saveChunkCheckpoint upserts a checkpoint.Chunk for chunkID if c has a
+// checkpoint.Store attached; it's a no-op otherwise. Checkpointing is
+// best-effort bookkeeping for operator introspection, not load-bearing
+// for correctness, so a save failure here is swallowed rather than
+// failing the shard.
+func (c *Coordinator) saveChunkCheckpoint(ctx context.Context, table, chunkID string, status checkpoint.Status, rowCount, byteOffset int64, sum hash.Hash, lastErr string) {
+	if c.checkpointStore == nil {
+		return
+	}
+	_ = c.checkpointStore.Save(ctx, &checkpoint.Chunk{
+		JobID:      c.jobID,
+		Table:      table,
+		ChunkID:    chunkID,
+		Status:     status,
+		RowCount:   rowCount,
+		ByteOffset: byteOffset,
+		Checksum:   hex.EncodeToString(sum.Sum(nil)),
+		LastError:  lastErr,
+		UpdatedAt:  time.Now(),
+	})
+}
+
+// renewLease extends key's lease at half its lease period until ctx is
+// cancelled, so a shard whose extraction runs longer than one lease
+// period isn't stolen out from under the worker still actively
+// extracting it.
+func (c *Coordinator) renewLease(ctx context.Context, key string) {
+	ticker := time.NewTicker(c.leaseTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.stateManager.RenewLock(ctx, key)
+		}
+	}
+}
+
+// planShards dispatches to the planner for c.strategy.
+func (c *Coordinator) planShards(ctx context.Context, table string, keyColumns []string, whereClause string) ([]Shard, error) {
+	switch c.strategy {
+	case StrategyRange:
+		return c.planRangeShards(ctx, table, keyColumns, whereClause)
+	case StrategyHash:
+		return c.planHashShards(ctx, table, keyColumns)
+	case StrategyNtile:
+		return c.planNtileShards(table, keyColumns)
+	default:
+		return nil, fmt.Errorf("unknown shard strategy: %s", c.strategy)
+	}
+}
+
+// planRangeShards splits [MIN(keyColumns[0]), MAX(keyColumns[0])] into
+// c.parallelism equal-width numeric ranges, using querySingleRow (rather
+// than a new Database method) to run the MIN/MAX query through the
+// SubmitAsync/Poll facility every driver already implements.
+func (c *Coordinator) planRangeShards(ctx context.Context, table string, keyColumns []string, whereClause string) ([]Shard, error) {
+	if len(keyColumns) != 1 {
+		return nil, fmt.Errorf("range shard strategy requires exactly one key column, got %d", len(keyColumns))
+	}
+	col := keyColumns[0]
+
+	query := fmt.Sprintf("SELECT MIN(%s) AS min_key, MAX(%s) AS max_key FROM %s", col, col, table)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+	row, err := c.querySingleRow(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine key range: %v", err)
+	}
+	min, err := toFloat64(row["min_key"])
+	if err != nil {
+		return nil, fmt.Errorf("key column %s is not numeric: %v", col, err)
+	}
+	max, err := toFloat64(row["max_key"])
+	if err != nil {
+		return nil, fmt.Errorf("key column %s is not numeric: %v", col, err)
+	}
+
+	width := (max - min + 1) / float64(c.parallelism)
+	if width < 1 {
+		width = 1
+	}
+
+	shards := make([]Shard, c.parallelism)
+	for i := 0; i < c.parallelism; i++ {
+		lo := min + float64(i)*width
+		if i == c.parallelism-1 {
+			shards[i] = Shard{Index: i, Where: fmt.Sprintf("%s >= %v", col, lo)}
+			continue
+		}
+		hi := min + float64(i+1)*width
+		shards[i] = Shard{Index: i, Where: fmt.Sprintf("%s >= %v AND %s < %v", col, lo, col, hi)}
+	}
+	return shards, nil
+}
+
+// planHashShards assigns shard i every row for which hash(keyColumns) %
+// parallelism == i, for keys a numeric range split can't handle.
+func (c *Coordinator) planHashShards(ctx context.Context, table string, keyColumns []string) ([]Shard, error) {
+	expr, err := hashExpr(c.dbType, keyColumns, c.bigintKey(ctx, table, keyColumns))
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]Shard, c.parallelism)
+	for i := 0; i < c.parallelism; i++ {
+		shards[i] = Shard{Index: i, Where: fmt.Sprintf("MOD(%s, %d) = %d", expr, c.parallelism, i)}
+	}
+	return shards, nil
+}
+
+// planNtileShards buckets rows into c.parallelism even-sized groups by
+// row count, via a correlated NTILE subquery, rather than by key value -
+// so shards stay balanced even when keyColumns[0]'s values are skewed.
+func (c *Coordinator) planNtileShards(table string, keyColumns []string) ([]Shard, error) {
+	if len(keyColumns) != 1 {
+		return nil, fmt.Errorf("ntile shard strategy requires exactly one key column, got %d", len(keyColumns))
+	}
+	col := keyColumns[0]
+
+	shards := make([]Shard, c.parallelism)
+	for i := 0; i < c.parallelism; i++ {
+		shards[i] = Shard{Index: i, Where: fmt.Sprintf(
+			"%s IN (SELECT %s FROM (SELECT %s, NTILE(%d) OVER (ORDER BY %s) AS sqlextract_ntile FROM %s) sqlextract_ntile_t WHERE sqlextract_ntile = %d)",
+			col, col, col, c.parallelism, col, table, i+1,
+		)}
+	}
+	return shards, nil
+}
+
+// hashExpr returns a SQL expression evaluating to a non-negative integer
+// hash of keyColumns, in the dialect dbType speaks. Multiple columns are
+// cast to text and concatenated before hashing. bigintKey selects
+// Postgres's 64-bit hashtextextended over its 32-bit hashtext, for a
+// single bigint key column where the wider hash spreads shards more
+// evenly; it's ignored by every other dialect, whose native hash
+// functions are already 64-bit.
+func hashExpr(dbType string, keyColumns []string, bigintKey bool) (string, error) {
+	col := keyColumns[0]
+	if len(keyColumns) > 1 {
+		parts := make([]string, len(keyColumns))
+		for i, k := range keyColumns {
+			parts[i] = fmt.Sprintf("CAST(%s AS VARCHAR)", k)
+		}
+		col = strings.Join(parts, " || ")
+	}
+
+	switch dbType {
+	case "postgres":
+		if bigintKey {
+			return fmt.Sprintf("ABS(hashtextextended(CAST(%s AS TEXT), 0))", col), nil
+		}
+		return fmt.Sprintf("ABS(hashtext(CAST(%s AS TEXT)))", col), nil
+	case "mssql":
+		return fmt.Sprintf("ABS(CHECKSUM(%s))", col), nil
+	case "bigquery":
+		return fmt.Sprintf("ABS(FARM_FINGERPRINT(CAST(%s AS STRING)))", col), nil
+	case "snowflake", "databricks", "duckdb":
+		return fmt.Sprintf("ABS(HASH(%s))", col), nil
+	default:
+		return "", fmt.Errorf("hash shard strategy is not supported for database type %q", dbType)
+	}
+}
+
+// bigintKey reports whether keyColumns is a single column whose declared
+// SQL type (as reported by c.db's database.SchemaDescriber, if it
+// implements one) is a bigint/int8 variant. Any ambiguity - multiple key
+// columns, no SchemaDescriber, an unrecognized or unreadable type - is
+// treated as false, falling back to the narrower 32-bit hash.
+func (c *Coordinator) bigintKey(ctx context.Context, table string, keyColumns []string) bool {
+	if len(keyColumns) != 1 {
+		return false
+	}
+	sd, ok := c.db.(database.SchemaDescriber)
+	if !ok {
+		return false
+	}
+	cols, err := sd.GetTableSchema(ctx, table)
+	if err != nil {
+		return false
+	}
+	for _, col := range cols {
+		if col.Name == keyColumns[0] {
+			switch strings.ToLower(col.Type) {
+			case "bigint", "int8":
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// querySingleRow runs sql through Database.SubmitAsync/Poll - the
+// generic arbitrary-statement facility every driver already implements -
+// and returns its single result row. It exists so shard planning queries
+// (MIN/MAX, eventually others) don't need a dedicated Database method.
+func (c *Coordinator) querySingleRow(ctx context.Context, sql string) (map[string]interface{}, error) {
+	handle, err := c.db.SubmitAsync(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit query: %v", err)
+	}
+
+	for {
+		status, rows, err := c.db.Poll(ctx, handle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to poll query: %v", err)
+		}
+		switch status {
+		case database.StatusDone:
+			if len(rows) == 0 {
+				return nil, fmt.Errorf("query returned no rows: %s", sql)
+			}
+			return rows[0], nil
+		case database.StatusFailed:
+			return nil, fmt.Errorf("query failed: %s", sql)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// toFloat64 converts a scanned database value to float64, for MIN/MAX
+// bounds of a numeric key column.
+func toFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	default:
+		return strconv.ParseFloat(fmt.Sprintf("%v", v), 64)
+	}
+}
+
+// splitAndTrim splits a comma-separated column list and trims whitespace
+// around each entry.
+func splitAndTrim(s string) []string {
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
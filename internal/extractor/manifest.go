@@ -0,0 +1,53 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gerhard-ee/sqlextract/internal/sink"
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// writeManifest assembles a state.Manifest from parts (one per shard,
+// already sorted by shard index) plus a freshly computed source
+// checksum, the same shape database.ExtractData's finalizeManifest builds
+// for a single-process extraction. It's saved through c.stateManager (so
+// `sqlextract -verify` can look it up by table the same way regardless of
+// -parallelism) and written as a "<outputPrefix>.manifest.json" sidecar.
+func (c *Coordinator) writeManifest(ctx context.Context, table, outputPrefix, format, keyColumns, whereClause string, parts []state.ManifestPart) error {
+	sourceChecksum, err := c.db.ChecksumTable(ctx, table, keyColumns, whereClause)
+	if err != nil {
+		return fmt.Errorf("failed to checksum source table: %v", err)
+	}
+
+	manifest := &state.Manifest{
+		Table:          table,
+		KeyColumns:     keyColumns,
+		WhereClause:    whereClause,
+		Format:         format,
+		Parts:          parts,
+		SourceChecksum: sourceChecksum,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := c.stateManager.SaveManifest(ctx, table, manifest); err != nil {
+		return fmt.Errorf("failed to save manifest: %v", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	manifestSink, err := sink.New(outputPrefix + ".manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to open manifest sink: %v", err)
+	}
+	if err := manifestSink.Write(ctx, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return manifestSink.Finalize()
+}
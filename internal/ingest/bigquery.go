@@ -1,7 +1,15 @@
 package ingest
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+
+	"github.com/gerhard-ee/sqlextract/internal/migrate"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
 )
 
 type BigQueryIngester struct{}
@@ -36,6 +44,122 @@ FROM FILES (
 	return script, nil
 }
 
+// Execute runs source's load through the BigQuery Go client's Loader
+// instead of just returning LOAD DATA script text - the `bq load`
+// equivalent the SQL form can't express: a local/streamed source uploads
+// through the client as a ReaderSource, while a remote gs:// source loads
+// straight from Cloud Storage via GCSReference. Schema is auto-detected
+// either way, and WriteDisposition maps from target.Truncate.
+func (i *BigQueryIngester) Execute(ctx context.Context, client *bigquery.Client, source Source, target Target) (Result, error) {
+	format := target.Format
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "parquet" {
+		return Result{}, fmt.Errorf("BigQueryIngester.Execute only supports csv or parquet sources, got %q", format)
+	}
+
+	parts := strings.SplitN(target.Table, ".", 2)
+	if len(parts) != 2 {
+		return Result{}, fmt.Errorf("target table %q must be dataset.table", target.Table)
+	}
+	dst := client.Dataset(parts[0]).Table(parts[1])
+
+	var loadSource bigquery.LoadSource
+	switch {
+	case source.Reader != nil:
+		rs := bigquery.NewReaderSource(source.Reader)
+		configureBigQuerySource(rs, format)
+		loadSource = rs
+	case sink.IsRemote(source.Path):
+		gcsRef := bigquery.NewGCSReference(source.Path)
+		configureBigQuerySource(gcsRef, format)
+		loadSource = gcsRef
+	case source.Path != "":
+		f, err := os.Open(source.Path)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to open source file %s: %v", source.Path, err)
+		}
+		defer f.Close()
+		rs := bigquery.NewReaderSource(f)
+		configureBigQuerySource(rs, format)
+		loadSource = rs
+	default:
+		return Result{}, fmt.Errorf("Source.Path or Source.Reader is required")
+	}
+
+	loader := dst.LoaderFrom(loadSource)
+	if target.Truncate {
+		loader.WriteDisposition = bigquery.WriteTruncate
+	} else {
+		loader.WriteDisposition = bigquery.WriteAppend
+	}
+
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to start load job: %v", err)
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to wait for load job: %v", err)
+	}
+	if err := status.Err(); err != nil {
+		return Result{}, fmt.Errorf("load job failed: %v", err)
+	}
+
+	var result Result
+	if stats, ok := status.Statistics.Details.(*bigquery.LoadStatistics); ok {
+		result.RowsLoaded = stats.OutputRows
+		result.BytesLoaded = stats.OutputBytes
+	}
+	for _, w := range status.Errors {
+		result.Rejected = append(result.Rejected, w.Error())
+	}
+	return result, nil
+}
+
+// configureBigQuerySource turns on schema auto-detection and sets the
+// file format (plus CSV's header row) on a ReaderSource or GCSReference,
+// the two bigquery.LoadSource implementations Execute uses.
+func configureBigQuerySource(rs interface{}, format string) {
+	sourceFormat := bigquery.CSV
+	if format == "parquet" {
+		sourceFormat = bigquery.Parquet
+	}
+	switch v := rs.(type) {
+	case *bigquery.ReaderSource:
+		v.AutoDetect = true
+		v.SourceFormat = sourceFormat
+		if format == "csv" {
+			v.SkipLeadingRows = 1
+		}
+	case *bigquery.GCSReference:
+		v.AutoDetect = true
+		v.SourceFormat = sourceFormat
+		if format == "csv" {
+			v.SkipLeadingRows = 1
+		}
+	}
+}
+
+// GenerateMigrationBundle builds a CREATE TABLE migration for targetTable
+// with one STRING column per entry in columns, so ingestion has a real
+// target table to load into instead of relying on LOAD DATA OVERWRITE to
+// infer one.
+func (i *BigQueryIngester) GenerateMigrationBundle(version int, columns []string, targetTable string) (migrate.Migration, error) {
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = fmt.Sprintf("%s STRING", col)
+	}
+
+	return migrate.Migration{
+		Version: version,
+		Name:    fmt.Sprintf("create_%s", targetTable),
+		Up:      fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n);", targetTable, strings.Join(defs, ",\n  ")),
+		Down:    fmt.Sprintf("DROP TABLE IF EXISTS %s;", targetTable),
+	}, nil
+}
+
 func (i *BigQueryIngester) GenerateParquetIngestScript(sourcePath, targetTable string) (string, error) {
 	script := fmt.Sprintf(`-- BigQuery Parquet Ingestion Script
 -- Generated by SQLExtract
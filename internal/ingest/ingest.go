@@ -1,7 +1,16 @@
 package ingest
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"io"
+	"os"
+
+	"cloud.google.com/go/bigquery"
+
+	"github.com/gerhard-ee/sqlextract/internal/migrate"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
 )
 
 // Ingester defines the interface for generating ingestion scripts
@@ -13,6 +22,198 @@ type Ingester interface {
 	GenerateParquetIngestScript(sourcePath, targetTable string) (string, error)
 }
 
+// MigrationBundler is implemented by ingesters that can emit a full
+// migrate.Migration bundle (a CREATE TABLE derived from the source
+// columns, plus matching down script) instead of just a one-shot load
+// script. Not every Ingester needs this, so it's a separate, optional
+// interface rather than a new Ingester method.
+type MigrationBundler interface {
+	// GenerateMigrationBundle builds a single migrate.Migration that
+	// creates targetTable with one column per entry in columns.
+	GenerateMigrationBundle(version int, columns []string, targetTable string) (migrate.Migration, error)
+}
+
+// MergeOptions configures GenerateDeltaMergeScript.
+type MergeOptions struct {
+	// EvolveSchema prefixes the script with
+	// `SET spark.databricks.delta.schema.autoMerge.enabled = true`, so the
+	// MERGE can add new source columns to targetTable instead of failing
+	// on a schema mismatch.
+	EvolveSchema bool
+	// SoftDeleteColumn, when set, names a boolean source column; matched
+	// rows with it true are deleted from the target instead of updated
+	// (`WHEN MATCHED AND src.<col> THEN DELETE`), ahead of the ordinary
+	// update clause.
+	SoftDeleteColumn string
+	// PartitionBy lists target partition columns to add to the MERGE's ON
+	// clause alongside keyColumns, which - even though they don't affect
+	// which rows match - lets Spark prune partitions the MERGE has to
+	// scan instead of reading the whole table.
+	PartitionBy []string
+	// ZOrderBy, when set, appends an `OPTIMIZE ... ZORDER BY` step after
+	// the MERGE to re-cluster the target on these columns.
+	ZOrderBy []string
+	// CreateIfNotExists wraps the MERGE with a
+	// `CREATE TABLE IF NOT EXISTS ... USING DELTA` derived from the
+	// source, for a first run against a target that doesn't exist yet.
+	CreateIfNotExists bool
+	// GlueBacked, combined with PartitionBy, appends an
+	// `MSCK REPAIR TABLE` step after the MERGE, so a target registered in
+	// AWS Glue Data Catalog (see internal/catalog) picks up any new
+	// partition directories the MERGE just wrote instead of requiring a
+	// separate UpdatePartitions call.
+	GlueBacked bool
+}
+
+// DeltaMerger is implemented by ingesters whose target supports Delta
+// Lake MERGE INTO, for incremental/CDC-style loads that upsert into an
+// existing table instead of overwriting it wholesale. Not every Ingester
+// target is Delta Lake, so this is a separate, optional interface rather
+// than a new Ingester method.
+type DeltaMerger interface {
+	// GenerateDeltaMergeScript produces a script that loads sourcePath
+	// into targetTable via `MERGE INTO ... ON <keyColumns>`, upserting
+	// matched rows and inserting new ones, instead of the INSERT OVERWRITE
+	// that GenerateCSVIngestScript/GenerateParquetIngestScript produce.
+	GenerateDeltaMergeScript(sourcePath, targetTable string, keyColumns []string, opts MergeOptions) (string, error)
+}
+
+// Executor is implemented by ingesters that can run their own generated
+// script directly against an open connection, instead of only returning
+// SQL text for an operator to paste into a worksheet. Not every Ingester
+// can do this (some scripts are meant for a CLI the Go driver can't
+// drive), so it's a separate, optional interface rather than a new
+// Ingester method.
+type Executor interface {
+	// ExecuteIngestScript runs script - as produced by
+	// GenerateCSVIngestScript or GenerateParquetIngestScript - against db,
+	// statement by statement, stopping at the first failing statement.
+	ExecuteIngestScript(db *sql.DB, script string) error
+}
+
+// Source describes where Execute reads load data from: a local file path,
+// a remote object URI (s3://, gs://, or an ADLS https://<account>.blob...
+// URL) that the target engine's native bulk-load path can read directly
+// without sqlextract downloading it first, or an io.Reader for a caller
+// that already has the data in hand (e.g. piped straight from
+// ExtractData without touching disk). Reader takes precedence over Path
+// when both are set.
+type Source struct {
+	Path   string
+	Reader io.Reader
+}
+
+// Target names the destination table for Execute and how it should
+// reconcile with any rows already there.
+type Target struct {
+	Table string
+	// Format is the source data's format: "csv" or "parquet". Ingesters
+	// that only support one format default to it when Format is empty.
+	Format string
+	// Truncate clears Table before loading instead of appending to it
+	// (e.g. BigQuery's WRITE_TRUNCATE vs WRITE_APPEND).
+	Truncate bool
+}
+
+// Result reports what Execute actually did, so a caller can log or assert
+// on it instead of trusting that a nil error means every row landed.
+type Result struct {
+	RowsLoaded  int64
+	BytesLoaded int64
+	// RowsRejected counts rows the engine parsed but refused to load
+	// (schema mismatch, malformed field, etc.), for engines that surface
+	// that distinction (Snowflake's COPY INTO, Databricks' COPY INTO).
+	RowsRejected int64
+	// Rejected holds a sample of per-row/per-file diagnostics for engines
+	// that report them; it's not guaranteed to be exhaustive for a load
+	// with many rejects.
+	Rejected []string
+}
+
+// BulkLoader is implemented by ingesters that can run a load directly
+// against an open database/sql connection using the target engine's
+// native bulk path (COPY FROM STDIN, COPY INTO, BULK INSERT), instead of
+// only returning SQL text via GenerateCSVIngestScript/
+// GenerateParquetIngestScript for an operator to run by hand. Not every
+// Ingester can do this over a *sql.DB - see BigQueryBulkLoader - so it's a
+// separate, optional interface rather than a new Ingester method.
+type BulkLoader interface {
+	// Execute loads source into target.Table through db, returning how
+	// many rows/bytes actually landed.
+	Execute(ctx context.Context, db *sql.DB, source Source, target Target) (Result, error)
+}
+
+// BigQueryBulkLoader mirrors BulkLoader for BigQueryIngester, whose native
+// Go client (cloud.google.com/go/bigquery) isn't a database/sql driver and
+// so can't implement BulkLoader's *sql.DB signature.
+type BigQueryBulkLoader interface {
+	Execute(ctx context.Context, client *bigquery.Client, source Source, target Target) (Result, error)
+}
+
+// countingReadCloser wraps a Source's reader to track how many bytes
+// Execute has pulled from it, for Result.BytesLoaded.
+type countingReadCloser struct {
+	io.Reader
+	closer io.Closer
+	n      int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReadCloser) Close() error {
+	if c.closer != nil {
+		return c.closer.Close()
+	}
+	return nil
+}
+
+// openSource opens source for reading: its Reader if set, or the local
+// file at its Path. Remote URIs (s3://, gs://, an ADLS https://...blob...
+// URL) aren't opened here - engines whose native bulk-load path can read
+// cloud storage directly (Snowflake, BigQuery, Databricks) pass
+// source.Path straight to it instead of calling openSource.
+func openSource(source Source) (*countingReadCloser, error) {
+	if source.Reader != nil {
+		closer, _ := source.Reader.(io.Closer)
+		return &countingReadCloser{Reader: source.Reader, closer: closer}, nil
+	}
+	if sink.IsRemote(source.Path) {
+		return nil, fmt.Errorf("this engine has no native reader for remote source %q; pass an io.Reader instead", source.Path)
+	}
+	if source.Path == "" {
+		return nil, fmt.Errorf("Source.Path or Source.Reader is required")
+	}
+	f, err := os.Open(source.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file %s: %v", source.Path, err)
+	}
+	return &countingReadCloser{Reader: f, closer: f}, nil
+}
+
+// toInt64 best-effort converts the driver-returned value of a COPY
+// INTO/BULK result column (int64, int32, float64, or a numeric []byte
+// literal, depending on driver) into an int64.
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	case []byte:
+		var out int64
+		if _, err := fmt.Sscanf(string(n), "%d", &out); err == nil {
+			return out, true
+		}
+	}
+	return 0, false
+}
+
 // NewIngester creates a new ingester based on the database type
 func NewIngester(dbType string) (Ingester, error) {
 	switch dbType {
@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -167,3 +168,92 @@ func TestScriptContent(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenSource(t *testing.T) {
+	testDir := t.TempDir()
+	localFile := filepath.Join(testDir, "rows.csv")
+	if err := os.WriteFile(localFile, []byte("id,name\n1,a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	r, err := openSource(Source{Path: localFile})
+	if err != nil {
+		t.Fatalf("openSource failed for local path: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read from opened source: %v", err)
+	}
+	if string(data) != "id,name\n1,a\n" {
+		t.Errorf("unexpected source contents: %q", data)
+	}
+	if r.n != int64(len(data)) {
+		t.Errorf("expected %d bytes counted, got %d", len(data), r.n)
+	}
+	r.Close()
+
+	if _, err := openSource(Source{Reader: strings.NewReader("x")}); err != nil {
+		t.Errorf("openSource failed for a Reader source: %v", err)
+	}
+
+	if _, err := openSource(Source{Path: "s3://bucket/key.csv"}); err == nil {
+		t.Error("expected openSource to reject a remote Path with no Reader")
+	}
+
+	if _, err := openSource(Source{}); err == nil {
+		t.Error("expected openSource to reject an empty Source")
+	}
+}
+
+func TestCSVRowToArgs(t *testing.T) {
+	args := csvRowToArgs([]string{"1", "NULL", "text"})
+	if args[0] != "1" || args[1] != nil || args[2] != "text" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestDatabricksDeltaMergeScript(t *testing.T) {
+	ingester, err := NewIngester("databricks")
+	if err != nil {
+		t.Fatalf("Failed to create Databricks ingester: %v", err)
+	}
+
+	merger, ok := ingester.(DeltaMerger)
+	if !ok {
+		t.Fatalf("Databricks ingester does not implement DeltaMerger")
+	}
+
+	if _, err := merger.GenerateDeltaMergeScript("source", "target", nil, MergeOptions{}); err == nil {
+		t.Error("Expected error when no key columns are given")
+	}
+
+	script, err := merger.GenerateDeltaMergeScript("source", "target", []string{"id"}, MergeOptions{
+		EvolveSchema:      true,
+		SoftDeleteColumn:  "_deleted",
+		PartitionBy:       []string{"region"},
+		ZOrderBy:          []string{"id", "region"},
+		CreateIfNotExists: true,
+		GlueBacked:        true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate Delta MERGE script: %v", err)
+	}
+
+	requiredElements := []string{
+		"SET spark.databricks.delta.schema.autoMerge.enabled = true",
+		"CREATE TABLE IF NOT EXISTS target USING DELTA",
+		"MERGE INTO target AS target",
+		"target.id = src.id",
+		"target.region = src.region",
+		"WHEN MATCHED AND src._deleted THEN DELETE",
+		"WHEN MATCHED THEN UPDATE SET *",
+		"WHEN NOT MATCHED THEN INSERT *",
+		"OPTIMIZE target ZORDER BY (id, region)",
+		"MSCK REPAIR TABLE target",
+	}
+	for _, element := range requiredElements {
+		if !strings.Contains(script, element) {
+			t.Errorf("Delta MERGE script missing required element: %s", element)
+		}
+	}
+}
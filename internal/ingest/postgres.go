@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/gerhard-ee/sqlextract/internal/migrate"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
+)
+
+type PostgresIngester struct{}
+
+func NewPostgresIngester() Ingester {
+	return &PostgresIngester{}
+}
+
+func (i *PostgresIngester) GenerateCSVIngestScript(sourcePath, targetTable string) (string, error) {
+	if sink.IsRemote(sourcePath) {
+		return "", fmt.Errorf("psql's \\copy requires a local file; download %s locally first (e.g. aws s3 cp / gsutil cp / az storage blob download) before generating a Postgres ingest script", sourcePath)
+	}
+
+	script := fmt.Sprintf(`-- Postgres CSV Ingestion Script
+-- Generated by SQLExtract
+-- Target Table: %s
+-- Source File: %s
+
+\copy %s FROM '%s' WITH (FORMAT csv, HEADER true, NULL 'NULL');`, targetTable, sourcePath, targetTable, sourcePath)
+
+	return script, nil
+}
+
+func (i *PostgresIngester) GenerateParquetIngestScript(sourcePath, targetTable string) (string, error) {
+	return "", fmt.Errorf("Postgres has no native Parquet bulk-load path; convert %s to CSV first, or load it through the parquet_fdw extension", sourcePath)
+}
+
+// Execute loads source's CSV rows into target.Table via pq.CopyIn, the
+// lib/pq driver's wrapper around Postgres' COPY ... FROM STDIN protocol -
+// the same native bulk path GenerateCSVIngestScript's \copy uses, run
+// directly against db instead of requiring psql.
+func (i *PostgresIngester) Execute(ctx context.Context, db *sql.DB, source Source, target Target) (Result, error) {
+	if target.Format != "" && target.Format != "csv" {
+		return Result{}, fmt.Errorf("PostgresIngester.Execute only supports csv sources, got %q", target.Format)
+	}
+
+	r, err := openSource(source)
+	if err != nil {
+		return Result{}, err
+	}
+	defer r.Close()
+
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if target.Truncate {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s", target.Table)); err != nil {
+			return Result{}, fmt.Errorf("failed to truncate %s: %v", target.Table, err)
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(target.Table, header...))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to prepare COPY FROM STDIN: %v", err)
+	}
+
+	var rowsLoaded int64
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to read CSV row %d: %v", rowsLoaded+1, err)
+		}
+		if _, err := stmt.ExecContext(ctx, csvRowToArgs(record)...); err != nil {
+			return Result{}, fmt.Errorf("failed to copy row %d: %v", rowsLoaded+1, err)
+		}
+		rowsLoaded++
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return Result{}, fmt.Errorf("failed to flush COPY FROM STDIN: %v", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return Result{}, fmt.Errorf("failed to close COPY statement: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return Result{RowsLoaded: rowsLoaded, BytesLoaded: r.n}, nil
+}
+
+// csvRowToArgs converts a CSV record into driver args for a prepared
+// statement, mapping the literal string "NULL" (GenerateCSVIngestScript's
+// NULL 'NULL' convention) to a real SQL NULL instead of the four-letter
+// string.
+func csvRowToArgs(record []string) []interface{} {
+	args := make([]interface{}, len(record))
+	for i, v := range record {
+		if v == "NULL" {
+			args[i] = nil
+		} else {
+			args[i] = v
+		}
+	}
+	return args
+}
+
+// GenerateMigrationBundle builds a CREATE TABLE migration for targetTable
+// with one TEXT column per entry in columns.
+func (i *PostgresIngester) GenerateMigrationBundle(version int, columns []string, targetTable string) (migrate.Migration, error) {
+	defs := make([]string, len(columns))
+	for idx, col := range columns {
+		defs[idx] = fmt.Sprintf("%s TEXT", col)
+	}
+
+	return migrate.Migration{
+		Version: version,
+		Name:    fmt.Sprintf("create_%s", targetTable),
+		Up:      fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n);", targetTable, strings.Join(defs, ",\n  ")),
+		Down:    fmt.Sprintf("DROP TABLE IF EXISTS %s;", targetTable),
+	}, nil
+}
@@ -0,0 +1,268 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gerhard-ee/sqlextract/internal/migrate"
+	"github.com/gerhard-ee/sqlextract/internal/sink"
+)
+
+type SnowflakeIngester struct{}
+
+func NewSnowflakeIngester() Ingester {
+	return &SnowflakeIngester{}
+}
+
+func (i *SnowflakeIngester) GenerateCSVIngestScript(sourcePath, targetTable string) (string, error) {
+	if sink.IsRemote(sourcePath) {
+		return fmt.Sprintf(`-- Snowflake CSV Ingestion Script
+-- Generated by SQLExtract
+-- Target Table: %s
+-- Source: %s (external stage, no local PUT required)
+
+COPY INTO %s
+FROM %s
+FILE_FORMAT = (TYPE = CSV FIELD_DELIMITER = ',' SKIP_HEADER = 1 NULL_IF = ('NULL', ''))
+ON_ERROR = 'ABORT_STATEMENT';`, targetTable, sourcePath, targetTable, externalStageLocation(sourcePath)), nil
+	}
+
+	script := fmt.Sprintf(`-- Snowflake CSV Ingestion Script
+-- Generated by SQLExtract
+-- Target Table: %s
+-- Source File: %s
+
+-- Stage the local file, then bulk-load it with COPY INTO
+PUT file://%s @~/sqlextract/%s AUTO_COMPRESS=TRUE OVERWRITE=TRUE;
+
+COPY INTO %s
+FROM @~/sqlextract/%s
+FILE_FORMAT = (TYPE = CSV FIELD_DELIMITER = ',' SKIP_HEADER = 1 NULL_IF = ('NULL', ''))
+ON_ERROR = 'ABORT_STATEMENT';
+
+REMOVE @~/sqlextract/%s;`, targetTable, sourcePath, sourcePath, targetTable, targetTable, targetTable, targetTable)
+
+	return script, nil
+}
+
+func (i *SnowflakeIngester) GenerateParquetIngestScript(sourcePath, targetTable string) (string, error) {
+	if sink.IsRemote(sourcePath) {
+		return fmt.Sprintf(`-- Snowflake Parquet Ingestion Script
+-- Generated by SQLExtract
+-- Target Table: %s
+-- Source: %s (external stage, no local PUT required)
+
+COPY INTO %s
+FROM %s
+FILE_FORMAT = (TYPE = PARQUET)
+MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE
+ON_ERROR = 'ABORT_STATEMENT';`, targetTable, sourcePath, targetTable, externalStageLocation(sourcePath)), nil
+	}
+
+	script := fmt.Sprintf(`-- Snowflake Parquet Ingestion Script
+-- Generated by SQLExtract
+-- Target Table: %s
+-- Source File: %s
+
+PUT file://%s @~/sqlextract/%s AUTO_COMPRESS=FALSE OVERWRITE=TRUE;
+
+COPY INTO %s
+FROM @~/sqlextract/%s
+FILE_FORMAT = (TYPE = PARQUET)
+MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE
+ON_ERROR = 'ABORT_STATEMENT';
+
+REMOVE @~/sqlextract/%s;`, targetTable, sourcePath, sourcePath, targetTable, targetTable, targetTable, targetTable)
+
+	return script, nil
+}
+
+// ExecuteIngestScript runs a PUT/COPY INTO/REMOVE script - as produced by
+// GenerateCSVIngestScript or GenerateParquetIngestScript - directly
+// against db. gosnowflake recognizes PUT and REMOVE as well as COPY INTO
+// over the same *sql.DB used for queries, so no separate CLI or stage
+// upload step is needed. Statements are split on the trailing ";" each
+// one ends with; comment-only and blank lines are skipped.
+func (i *SnowflakeIngester) ExecuteIngestScript(db *sql.DB, script string) error {
+	for _, stmt := range strings.Split(script, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || isCommentOnly(stmt) {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to execute statement %q: %v", stmt, err)
+		}
+	}
+	return nil
+}
+
+// isCommentOnly reports whether stmt contains nothing but blank lines and
+// "--" comment lines, once PUT/COPY INTO/REMOVE statements have already
+// been split out by ExecuteIngestScript.
+func isCommentOnly(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// externalStageLocation quotes sourcePath as a COPY INTO FROM literal. A
+// Snowflake storage integration or external stage granting access to
+// sourcePath's bucket/container is assumed to already exist; this only
+// points COPY INTO at the same cloud path ExtractData wrote to.
+func externalStageLocation(sourcePath string) string {
+	return fmt.Sprintf("'%s'", sourcePath)
+}
+
+// Execute runs a PUT/COPY INTO load directly against db instead of just
+// returning script text: a local source is spooled to disk (if it came in
+// as an io.Reader) and PUT to a per-table stage, a remote source is
+// assumed already reachable through an external stage/storage integration
+// and is COPY INTO'd from in place, and either way the COPY INTO result
+// set is parsed into Result instead of being discarded.
+func (i *SnowflakeIngester) Execute(ctx context.Context, db *sql.DB, source Source, target Target) (Result, error) {
+	format := target.Format
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "parquet" {
+		return Result{}, fmt.Errorf("SnowflakeIngester.Execute only supports csv or parquet sources, got %q", format)
+	}
+
+	localPath := source.Path
+	cleanup := func() {}
+	if source.Reader != nil {
+		tmp, err := spoolToTempFile(source.Reader, format)
+		if err != nil {
+			return Result{}, err
+		}
+		localPath = tmp
+		cleanup = func() { os.Remove(tmp) }
+	} else if localPath == "" {
+		return Result{}, fmt.Errorf("Source.Path or Source.Reader is required")
+	}
+	defer cleanup()
+
+	stageLocation := fmt.Sprintf("@~/sqlextract/%s", target.Table)
+	if sink.IsRemote(source.Path) {
+		stageLocation = externalStageLocation(source.Path)
+	} else {
+		putSQL := fmt.Sprintf("PUT file://%s %s AUTO_COMPRESS=%s OVERWRITE=TRUE", localPath, stageLocation, autoCompress(format))
+		if _, err := db.ExecContext(ctx, putSQL); err != nil {
+			return Result{}, fmt.Errorf("failed to PUT %s to stage: %v", localPath, err)
+		}
+		defer db.ExecContext(ctx, fmt.Sprintf("REMOVE %s", stageLocation))
+	}
+
+	fileFormat := "FILE_FORMAT = (TYPE = CSV FIELD_DELIMITER = ',' SKIP_HEADER = 1 NULL_IF = ('NULL', ''))"
+	if format == "parquet" {
+		fileFormat = "FILE_FORMAT = (TYPE = PARQUET)\nMATCH_BY_COLUMN_NAME = CASE_INSENSITIVE"
+	}
+
+	if target.Truncate {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s", target.Table)); err != nil {
+			return Result{}, fmt.Errorf("failed to truncate %s: %v", target.Table, err)
+		}
+	}
+
+	copySQL := fmt.Sprintf("COPY INTO %s\nFROM %s\n%s\nON_ERROR = 'CONTINUE'", target.Table, stageLocation, fileFormat)
+	rows, err := db.QueryContext(ctx, copySQL)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to COPY INTO %s: %v", target.Table, err)
+	}
+	defer rows.Close()
+
+	return scanCopyIntoResult(rows)
+}
+
+// spoolToTempFile copies r to a new temp file and returns its path, so an
+// io.Reader source can go through the same local-file PUT path as a
+// Source with Path set.
+func spoolToTempFile(r io.Reader, format string) (string, error) {
+	f, err := os.CreateTemp("", "sqlextract-ingest-*."+format)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file for streamed source: %v", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to spool streamed source to disk: %v", err)
+	}
+	return f.Name(), nil
+}
+
+func autoCompress(format string) string {
+	if format == "parquet" {
+		return "FALSE"
+	}
+	return "TRUE"
+}
+
+// scanCopyIntoResult aggregates Snowflake's COPY INTO <table> result set -
+// one row per staged file, with rows_parsed/rows_loaded/first_error
+// columns among others - into a single Result. Column position varies by
+// Snowflake version, so columns are looked up by name rather than assumed
+// order.
+func scanCopyIntoResult(rows *sql.Rows) (Result, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read COPY INTO result columns: %v", err)
+	}
+	idx := make(map[string]int, len(cols))
+	for i, c := range cols {
+		idx[strings.ToLower(c)] = i
+	}
+
+	var result Result
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return Result{}, fmt.Errorf("failed to scan COPY INTO result row: %v", err)
+		}
+
+		var loaded, parsed int64
+		if i, ok := idx["rows_loaded"]; ok {
+			loaded, _ = toInt64(vals[i])
+		}
+		if i, ok := idx["rows_parsed"]; ok {
+			parsed, _ = toInt64(vals[i])
+		}
+		result.RowsLoaded += loaded
+		if parsed > loaded {
+			result.RowsRejected += parsed - loaded
+		}
+		if i, ok := idx["first_error"]; ok {
+			if msg, ok := vals[i].(string); ok && msg != "" {
+				result.Rejected = append(result.Rejected, msg)
+			}
+		}
+	}
+	return result, rows.Err()
+}
+
+// GenerateMigrationBundle builds a CREATE TABLE migration for targetTable
+// with one VARCHAR column per entry in columns.
+func (i *SnowflakeIngester) GenerateMigrationBundle(version int, columns []string, targetTable string) (migrate.Migration, error) {
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = fmt.Sprintf("%s VARCHAR", col)
+	}
+
+	return migrate.Migration{
+		Version: version,
+		Name:    fmt.Sprintf("create_%s", targetTable),
+		Up:      fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n);", targetTable, strings.Join(defs, ",\n  ")),
+		Down:    fmt.Sprintf("DROP TABLE IF EXISTS %s;", targetTable),
+	}, nil
+}
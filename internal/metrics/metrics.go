@@ -0,0 +1,141 @@
+// Package metrics exposes Prometheus counters, gauges, and a histogram for
+// sqlextract extraction jobs. Most invocations of sqlextract are short-lived
+// cron jobs that exit before anything can scrape them, so in addition to
+// serving /metrics for long-lived processes, this package can push the
+// final values to a Pushgateway on exit.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	rowsExtractedTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "sqlextract_rows_extracted_total",
+		Help: "Total number of rows extracted.",
+	}, []string{"database", "table"})
+
+	batchesTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "sqlextract_batches_total",
+		Help: "Total number of ExtractBatch calls.",
+	}, []string{"database", "table"})
+
+	batchDurationSeconds = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name: "sqlextract_batch_duration_seconds",
+		Help: "Duration of each ExtractBatch call, in seconds.",
+	}, []string{"database", "table"})
+
+	bytesWrittenTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "sqlextract_bytes_written_total",
+		Help: "Total bytes written to the output sink.",
+	}, []string{"database", "table"})
+
+	activeJobs = promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqlextract_active_jobs",
+		Help: "Number of extraction jobs currently running.",
+	}, []string{"database", "table"})
+
+	lastSuccessTimestamp = promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqlextract_last_success_timestamp",
+		Help: "Unix timestamp of the last extraction that completed without error.",
+	}, []string{"database", "table"})
+
+	errorsTotal = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "sqlextract_errors_total",
+		Help: "Total number of extraction errors, including failed connects.",
+	}, []string{"database", "table"})
+
+	poolOpenConnections = promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqlextract_pool_open_connections",
+		Help: "Number of established connections, in use and idle, in a database.ConnectionRegistry pool.",
+	}, []string{"pool"})
+
+	poolInUse = promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqlextract_pool_in_use",
+		Help: "Number of connections currently in use in a database.ConnectionRegistry pool.",
+	}, []string{"pool"})
+
+	poolIdle = promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqlextract_pool_idle",
+		Help: "Number of idle connections in a database.ConnectionRegistry pool.",
+	}, []string{"pool"})
+
+	poolWaitCount = promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqlextract_pool_wait_count",
+		Help: "Total number of connections a database.ConnectionRegistry pool has made callers wait for.",
+	}, []string{"pool"})
+)
+
+// JobStarted marks the start of an extraction job for (database, table),
+// incrementing active_jobs. The caller must invoke the returned func when
+// the job ends, typically via defer, to decrement it again.
+func JobStarted(database, table string) func() {
+	activeJobs.WithLabelValues(database, table).Inc()
+	return func() { activeJobs.WithLabelValues(database, table).Dec() }
+}
+
+// RecordBatch records one ExtractBatch call: how many rows it returned,
+// how long it took, and how many bytes of output it produced.
+func RecordBatch(database, table string, rows int, duration time.Duration, bytes int) {
+	batchesTotal.WithLabelValues(database, table).Inc()
+	rowsExtractedTotal.WithLabelValues(database, table).Add(float64(rows))
+	batchDurationSeconds.WithLabelValues(database, table).Observe(duration.Seconds())
+	bytesWrittenTotal.WithLabelValues(database, table).Add(float64(bytes))
+}
+
+// RecordSuccess sets last_success_timestamp to now for (database, table).
+func RecordSuccess(database, table string) {
+	lastSuccessTimestamp.WithLabelValues(database, table).Set(float64(time.Now().Unix()))
+}
+
+// RecordError increments errors_total for (database, table). table may be
+// empty, e.g. for a Connect failure that happens before a table is known.
+func RecordError(database, table string) {
+	errorsTotal.WithLabelValues(database, table).Inc()
+}
+
+// RecordPoolStats publishes a database.ConnectionRegistry pool's current
+// sql.DB.Stats() under the given pool key (its (host,port,user,sslmode,
+// database) identity), so a scraped /metrics shows in-use/idle/wait-count
+// per shared pool the same way a per-table extraction shows rows/batches.
+func RecordPoolStats(pool string, open, inUse, idle int, waitCount int64) {
+	poolOpenConnections.WithLabelValues(pool).Set(float64(open))
+	poolInUse.WithLabelValues(pool).Set(float64(inUse))
+	poolIdle.WithLabelValues(pool).Set(float64(idle))
+	poolWaitCount.WithLabelValues(pool).Set(float64(waitCount))
+}
+
+// Serve starts an HTTP server on addr exposing the registered metrics at
+// /metrics and returns immediately; the server runs until the process
+// exits. Use this for long-running invocations an operator can scrape.
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux}
+	go server.ListenAndServe()
+	return nil
+}
+
+// Push sends the current metric values to the Pushgateway at url under
+// job, replacing any values previously pushed under the same grouping.
+// Call it on a clean exit so a one-shot run's final counts are recorded
+// even though nothing ever scraped its /metrics endpoint.
+func Push(url, job string) error {
+	return push.New(url, job).Gatherer(registry).Push()
+}
+
+// PushPartial sends the current metric values to the Pushgateway at url
+// under job using Add instead of Push, so the counters merge with (rather
+// than replace) a grouping that was already pushed - appropriate when a
+// run failed partway through and a retry will push again.
+func PushPartial(url, job string) error {
+	return push.New(url, job).Gatherer(registry).Add()
+}
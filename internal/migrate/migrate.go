@@ -0,0 +1,366 @@
+// Package migrate implements a small golang-migrate-style schema migration
+// runner. Versioned SQL files live under migrations/<driver>/NNN_name.up.sql
+// and migrations/<driver>/NNN_name.down.sql; the applied version is tracked
+// per-driver through a state.Manager rather than a dedicated SQL table, so
+// the runner works against any Database without needing a query API beyond
+// Exec.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gerhard-ee/sqlextract/internal/database"
+	"github.com/gerhard-ee/sqlextract/internal/state"
+)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Load reads and pairs up every NNN_name.up.sql/NNN_name.down.sql file
+// under dir/driver, sorted by version.
+func Load(dir, driver string) ([]Migration, error) {
+	driverDir := filepath.Join(dir, driver)
+	entries, err := os.ReadDir(driverDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory %s: %v", driverDir, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileRe.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(driverDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %v", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		}
+		if matches[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// versionStateKey is the reserved state.Manager table name used to track
+// the applied migration version for driver, distinct from any real table
+// being extracted.
+func versionStateKey(driver string) string {
+	return fmt.Sprintf("__schema_migrations_%s__", driver)
+}
+
+// migrationLeaseTTL bounds how long Up/Down/Steps/Force hold the
+// versionStateKey lease for, mirroring extractor.Coordinator's shard
+// leases: long enough to cover one migration file's Exec, short enough
+// that a crashed runner doesn't wedge the version permanently.
+const migrationLeaseTTL = 30 * time.Second
+
+// Runner applies versioned migrations against a Database using its Exec
+// method, tracking the applied version - and, via the same state.State
+// record's Status field, whether the last attempt left it "dirty" - in a
+// state.Manager. Since Database.Exec has no result-returning variant,
+// Runner can't take out a driver-native advisory lock (pg_try_advisory_lock,
+// sp_getapplock, ...) the way golang-migrate does; it reuses
+// state.Manager's LockState/UnlockState lease instead, the same
+// leader-election mechanism extractor.Coordinator uses for shard leases,
+// to keep two concurrent runners against the same driver from racing on
+// the tracked version.
+type Runner struct {
+	db           database.Database
+	stateManager state.Manager
+	driver       string
+	dir          string
+}
+
+// NewRunner creates a migration runner for driver, loading migrations from
+// dir/driver and tracking progress in stateManager.
+func NewRunner(db database.Database, stateManager state.Manager, driver, dir string) *Runner {
+	return &Runner{
+		db:           db,
+		stateManager: stateManager,
+		driver:       driver,
+		dir:          dir,
+	}
+}
+
+// Version returns the currently applied migration version (0 if no
+// migrations have run yet) and whether it's "dirty" - left behind by a
+// migration whose Exec failed partway through, which Up/Down/Steps will
+// refuse to build on until an operator resolves it with Force.
+func (r *Runner) Version(ctx context.Context) (int, bool, error) {
+	s, err := r.stateManager.GetState(ctx, versionStateKey(r.driver))
+	if err != nil || s == nil {
+		return 0, false, nil
+	}
+	return int(s.ProcessedRows), s.Status == "dirty", nil
+}
+
+func (r *Runner) setVersion(ctx context.Context, version int) error {
+	key := versionStateKey(r.driver)
+	s, err := r.stateManager.GetState(ctx, key)
+	if err != nil || s == nil {
+		return r.stateManager.CreateState(ctx, &state.State{
+			Table:         key,
+			Status:        "completed",
+			ProcessedRows: int64(version),
+		})
+	}
+	if err := r.stateManager.UpdateState(ctx, key, int64(version)); err != nil {
+		return err
+	}
+	return r.stateManager.UpdateStatus(ctx, key, "completed", "")
+}
+
+// ensureVersionState makes sure a state.State record exists for the
+// driver's version key before the first LockState call: state.MemoryManager
+// refuses to lock a table it has never seen a state record for, and the
+// very first migrate invocation against a fresh driver has none yet.
+func (r *Runner) ensureVersionState(ctx context.Context) error {
+	key := versionStateKey(r.driver)
+	s, err := r.stateManager.GetState(ctx, key)
+	if err != nil {
+		return err
+	}
+	if s != nil {
+		return nil
+	}
+	return r.stateManager.CreateState(ctx, &state.State{
+		Table:         key,
+		Status:        "completed",
+		ProcessedRows: 0,
+	})
+}
+
+// withLock runs fn while holding versionStateKey's lease, so two Runners
+// racing against the same driver can't both apply a migration and record
+// conflicting versions.
+func (r *Runner) withLock(ctx context.Context, fn func() error) error {
+	if err := r.ensureVersionState(ctx); err != nil {
+		return fmt.Errorf("failed to initialize migration state: %v", err)
+	}
+
+	key := versionStateKey(r.driver)
+	ok, err := r.stateManager.LockState(ctx, key, migrationLeaseTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %v", err)
+	}
+	if !ok {
+		return fmt.Errorf("another migration is already running for driver %s", r.driver)
+	}
+	defer r.stateManager.UnlockState(ctx, key)
+
+	return fn()
+}
+
+// markDirty flags the version record as dirty before attempting m's Exec,
+// so a process that crashes mid-migration leaves a visible signal instead
+// of silently appearing up to date at its last successfully recorded
+// version.
+func (r *Runner) markDirty(ctx context.Context, m Migration) error {
+	return r.stateManager.UpdateStatus(ctx, versionStateKey(r.driver), "dirty", fmt.Sprintf("applying migration %d_%s", m.Version, m.Name))
+}
+
+// Up applies every migration with a version greater than the currently
+// applied one, in order.
+func (r *Runner) Up(ctx context.Context) error {
+	return r.withLock(ctx, func() error { return r.upTo(ctx, 0) })
+}
+
+// Down rolls back the single most recently applied migration.
+func (r *Runner) Down(ctx context.Context) error {
+	return r.withLock(ctx, func() error { return r.downOnce(ctx) })
+}
+
+// Goto migrates up or down until target is the currently applied version.
+func (r *Runner) Goto(ctx context.Context, target int) error {
+	return r.withLock(ctx, func() error {
+		for {
+			current, dirty, err := r.Version(ctx)
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return fmt.Errorf("version %d is dirty; resolve with Force before migrating further", current)
+			}
+			if current == target {
+				return nil
+			}
+			if current < target {
+				if err := r.upOnce(ctx, current); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.downOnce(ctx); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// Steps applies n migrations forward (n > 0) or rolls back -n migrations
+// (n < 0) from the current version. Unlike Goto, which walks toward an
+// absolute target version, Steps walks a relative count - n == 0 is a
+// no-op.
+func (r *Runner) Steps(ctx context.Context, n int) error {
+	return r.withLock(ctx, func() error {
+		if n > 0 {
+			for i := 0; i < n; i++ {
+				current, dirty, err := r.Version(ctx)
+				if err != nil {
+					return err
+				}
+				if dirty {
+					return fmt.Errorf("version %d is dirty; resolve with Force before migrating further", current)
+				}
+				if err := r.upOnce(ctx, current); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for i := 0; i < -n; i++ {
+			if err := r.downOnce(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Force sets the tracked version directly to v and clears any dirty flag,
+// without running a migration's SQL. It's for an operator who has
+// manually reconciled the schema after a migration's Exec failed partway
+// through and wants Up/Down/Steps to trust the tracked version again.
+func (r *Runner) Force(ctx context.Context, v int) error {
+	return r.withLock(ctx, func() error { return r.setVersion(ctx, v) })
+}
+
+// upTo applies every migration above the current version up through
+// target, or through the newest migration available when target is 0.
+func (r *Runner) upTo(ctx context.Context, target int) error {
+	migrations, err := Load(r.dir, r.driver)
+	if err != nil {
+		return err
+	}
+
+	current, dirty, err := r.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read current migration version: %v", err)
+	}
+	if dirty {
+		return fmt.Errorf("version %d is dirty; resolve with Force before migrating further", current)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if target != 0 && m.Version > target {
+			break
+		}
+		if err := r.markDirty(ctx, m); err != nil {
+			return fmt.Errorf("failed to mark migration %d_%s dirty: %v", m.Version, m.Name, err)
+		}
+		if err := r.db.Exec(ctx, m.Up); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %v (schema is now dirty at version %d; resolve with Force)", m.Version, m.Name, err, current)
+		}
+		if err := r.setVersion(ctx, m.Version); err != nil {
+			return fmt.Errorf("failed to record migration version %d: %v", m.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// downOnce rolls back the single most recently applied migration.
+func (r *Runner) downOnce(ctx context.Context) error {
+	migrations, err := Load(r.dir, r.driver)
+	if err != nil {
+		return err
+	}
+
+	current, dirty, err := r.Version(ctx)
+	if err != nil || current == 0 {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("version %d is dirty; resolve with Force before rolling back", current)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version != current {
+			continue
+		}
+		if err := r.markDirty(ctx, m); err != nil {
+			return fmt.Errorf("failed to mark migration %d_%s dirty: %v", m.Version, m.Name, err)
+		}
+		if err := r.db.Exec(ctx, m.Down); err != nil {
+			return fmt.Errorf("failed to roll back migration %d_%s: %v (schema is now dirty at version %d; resolve with Force)", m.Version, m.Name, err, current)
+		}
+
+		prev := 0
+		for _, candidate := range migrations {
+			if candidate.Version < m.Version && candidate.Version > prev {
+				prev = candidate.Version
+			}
+		}
+		return r.setVersion(ctx, prev)
+	}
+
+	return fmt.Errorf("no migration found for current version %d", current)
+}
+
+func (r *Runner) upOnce(ctx context.Context, current int) error {
+	migrations, err := Load(r.dir, r.driver)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := r.markDirty(ctx, m); err != nil {
+			return fmt.Errorf("failed to mark migration %d_%s dirty: %v", m.Version, m.Name, err)
+		}
+		if err := r.db.Exec(ctx, m.Up); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %v (schema is now dirty at version %d; resolve with Force)", m.Version, m.Name, err, current)
+		}
+		return r.setVersion(ctx, m.Version)
+	}
+	return fmt.Errorf("no migration found above version %d", current)
+}
@@ -0,0 +1,46 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+// gcsSink streams parts into a GCS object through storage.Writer, which
+// itself performs a resumable/chunked upload under the hood.
+type gcsSink struct {
+	client *storage.Client
+	writer *storage.Writer
+}
+
+func newGCSSink(uri string) (Sink, error) {
+	bucket, key, err := splitURI(uri, "gs://")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %v", err)
+	}
+
+	writer := client.Bucket(bucket).Object(key).NewWriter(ctx)
+	return &gcsSink{client: client, writer: writer}, nil
+}
+
+func (s *gcsSink) Write(ctx context.Context, part io.Reader) error {
+	if _, err := io.Copy(s.writer, part); err != nil {
+		return fmt.Errorf("failed to write part to GCS object: %v", err)
+	}
+	return nil
+}
+
+func (s *gcsSink) Finalize() error {
+	if err := s.writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize GCS object: %v", err)
+	}
+	return s.client.Close()
+}
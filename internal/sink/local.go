@@ -0,0 +1,36 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// localSink writes parts to a local file in order, via sequential appends.
+type localSink struct {
+	file *os.File
+}
+
+func newLocalSink(path string) (Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %v", err)
+	}
+	return &localSink{file: file}, nil
+}
+
+func (s *localSink) Write(ctx context.Context, part io.Reader) error {
+	if _, err := io.Copy(s.file, part); err != nil {
+		return fmt.Errorf("failed to write part: %v", err)
+	}
+	return nil
+}
+
+func (s *localSink) Finalize() error {
+	return s.file.Close()
+}
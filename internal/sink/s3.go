@@ -0,0 +1,72 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3PartSize is the rolling multipart upload part size handed to
+// manager.Uploader; S3 requires at least 5 MiB per part except the last.
+const s3PartSize = 64 * 1024 * 1024
+
+// s3Sink streams parts into an S3 multipart upload through an io.Pipe: each
+// Write call copies its part into the pipe, and a background goroutine
+// started at construction time feeds the pipe's read side to
+// manager.Uploader, which itself splits it into s3PartSize chunks.
+type s3Sink struct {
+	pipeWriter *io.PipeWriter
+	done       chan error
+}
+
+func newS3Sink(uri string) (Sink, error) {
+	bucket, key, err := splitURI(uri, "s3://")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+
+	pr, pw := io.Pipe()
+	uploader := manager.NewUploader(s3.NewFromConfig(cfg), func(u *manager.Uploader) {
+		u.PartSize = s3PartSize
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Body:   pr,
+		})
+		done <- err
+	}()
+
+	return &s3Sink{pipeWriter: pw, done: done}, nil
+}
+
+func (s *s3Sink) Write(ctx context.Context, part io.Reader) error {
+	if _, err := io.Copy(s.pipeWriter, part); err != nil {
+		return fmt.Errorf("failed to write part to S3 upload pipe: %v", err)
+	}
+	return nil
+}
+
+func (s *s3Sink) Finalize() error {
+	if err := s.pipeWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close S3 upload pipe: %v", err)
+	}
+	if err := <-s.done; err != nil {
+		return fmt.Errorf("failed to complete S3 multipart upload: %v", err)
+	}
+	return nil
+}
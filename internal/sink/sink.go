@@ -0,0 +1,56 @@
+// Package sink abstracts where extracted data is written. Callers get a
+// Sink by URI (a local path, or an s3://, gs://, or abfs:// URL) and stream
+// output to it one batch at a time instead of requiring local disk equal
+// to the table size.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sink receives extracted output as a sequence of parts (one per batch)
+// and assembles them into the final destination object or file.
+type Sink interface {
+	// Write appends part to the sink. Implementations that upload to
+	// object storage treat each call as one piece of a multipart upload.
+	Write(ctx context.Context, part io.Reader) error
+	// Finalize completes the sink, e.g. committing a multipart upload or
+	// closing a local file. The sink must not be used after Finalize.
+	Finalize() error
+}
+
+// IsRemote reports whether uri names an object-storage location (as
+// opposed to a local path), so callers can decide whether a local-disk
+// fast path (e.g. a driver's native stage/directory unload) applies.
+func IsRemote(uri string) bool {
+	return strings.HasPrefix(uri, "s3://") || strings.HasPrefix(uri, "gs://") || strings.HasPrefix(uri, "abfs://")
+}
+
+// New returns a Sink for uri, selected by its scheme: "s3://" for S3,
+// "gs://" for GCS, "abfs://" for Azure Blob, and anything else (including
+// a bare path) for the local filesystem.
+func New(uri string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(uri, "s3://"):
+		return newS3Sink(uri)
+	case strings.HasPrefix(uri, "gs://"):
+		return newGCSSink(uri)
+	case strings.HasPrefix(uri, "abfs://"):
+		return newAzureSink(uri)
+	default:
+		return newLocalSink(uri)
+	}
+}
+
+// splitURI splits "scheme://bucket/key/with/slashes" into bucket and key.
+func splitURI(uri, scheme string) (bucket, key string, err error) {
+	trimmed := strings.TrimPrefix(uri, scheme)
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid %s URI: %s", scheme, uri)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,208 @@
+package state
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// batchMaxAttempts is how many times Dequeue/ack will retry a BatchEvent
+// (via exponential backoff) before moving it to that table's dead list.
+const batchMaxAttempts = 5
+
+// batchMaxBackoff caps the exponential backoff applied between retries,
+// so a event that's failed many times still gets revisited periodically
+// instead of effectively never again.
+const batchMaxBackoff = 5 * time.Minute
+
+// BatchEvent is one failed extraction batch queued for retry. ID is
+// assigned by Enqueue if the caller leaves it as uuid.Nil. Meta carries
+// correlation IDs the extractor wants preserved across retries for
+// tracing.
+type BatchEvent struct {
+	ID            uuid.UUID
+	Table         string
+	Offset        int64
+	Rows          int64
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	Meta          map[string]string
+}
+
+// batchBackoff returns the delay before a BatchEvent that has failed
+// attempts times is eligible for another Dequeue, doubling each attempt
+// and capped at batchMaxBackoff.
+func batchBackoff(attempts int) time.Duration {
+	d := time.Second << uint(attempts)
+	if d <= 0 || d > batchMaxBackoff {
+		return batchMaxBackoff
+	}
+	return d
+}
+
+// batchQueue is a container/heap.Interface min-heap of *BatchEvent
+// ordered by NextAttemptAt, so Dequeue can always find the oldest ready
+// event for a table without scanning the rest of its queue.
+type batchQueue []*BatchEvent
+
+func (q batchQueue) Len() int            { return len(q) }
+func (q batchQueue) Less(i, j int) bool  { return q[i].NextAttemptAt.Before(q[j].NextAttemptAt) }
+func (q batchQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *batchQueue) Push(x interface{}) { *q = append(*q, x.(*BatchEvent)) }
+func (q *batchQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+// leasedBatch is a BatchEvent Dequeue has handed to a worker, tracked so
+// the janitor can requeue it (as a retry or, past batchMaxAttempts, a
+// dead-letter) if the worker dies before it ever calls ack.
+type leasedBatch struct {
+	event     *BatchEvent
+	expiresAt time.Time
+}
+
+// BatchQueue is implemented by Managers that can track failed extraction
+// batches for retry, with a dead-letter list for ones that exhaust their
+// retries. Not every backend can offer this, so it's a separate,
+// optional interface rather than new Manager methods.
+type BatchQueue interface {
+	// Enqueue adds ev to its table's retry queue.
+	Enqueue(ev *BatchEvent) error
+	// Dequeue pops the oldest ready event queued for table, if any; see
+	// the MemoryManager implementation below for the full contract.
+	Dequeue(ctx context.Context, table string, lease time.Duration) (event *BatchEvent, ack func(error) error, err error)
+	// ListDead returns table's dead-lettered events.
+	ListDead(table string) ([]*BatchEvent, error)
+}
+
+// queueForLocked returns table's batchQueue, creating it if this is the
+// first event queued for it. Must be called with m.mu held.
+func (m *MemoryManager) queueForLocked(table string) *batchQueue {
+	q, ok := m.queues[table]
+	if !ok {
+		q = &batchQueue{}
+		m.queues[table] = q
+	}
+	return q
+}
+
+// Enqueue adds ev to its table's retry queue, assigning it a fresh ID if
+// it doesn't already have one and defaulting NextAttemptAt to now so it's
+// immediately eligible for Dequeue.
+func (m *MemoryManager) Enqueue(ev *BatchEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ev.ID == uuid.Nil {
+		ev.ID = uuid.New()
+	}
+	if ev.NextAttemptAt.IsZero() {
+		ev.NextAttemptAt = time.Now()
+	}
+	heap.Push(m.queueForLocked(ev.Table), ev)
+	return nil
+}
+
+// Dequeue atomically pops the oldest NextAttemptAt<=now event queued for
+// table, if any, handing the caller a lease good for lease before the
+// event is treated as abandoned and requeued. The returned ack must be
+// called exactly once: ack(nil) removes the event, while a non-nil error
+// reschedules it with exponential backoff or, past batchMaxAttempts,
+// moves it to table's dead list. Dequeue returns a nil event (not an
+// error) when nothing is ready yet.
+func (m *MemoryManager) Dequeue(ctx context.Context, table string, lease time.Duration) (*BatchEvent, func(error) error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	q, ok := m.queues[table]
+	if !ok || q.Len() == 0 || (*q)[0].NextAttemptAt.After(time.Now()) {
+		m.mu.Unlock()
+		return nil, nil, nil
+	}
+
+	ev := heap.Pop(q).(*BatchEvent)
+	m.leases[ev.ID] = &leasedBatch{event: ev, expiresAt: time.Now().Add(lease)}
+	m.mu.Unlock()
+
+	ack := func(ackErr error) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		leased, held := m.leases[ev.ID]
+		if !held {
+			// The lease already expired and the janitor reclaimed it;
+			// this ack is too late to apply.
+			return nil
+		}
+		delete(m.leases, ev.ID)
+		m.resolveLocked(leased.event, ackErr)
+		return nil
+	}
+	return ev, ack, nil
+}
+
+// resolveLocked applies the outcome of one Dequeue attempt: a nil
+// ackErr drops the event entirely, otherwise it's requeued with
+// exponential backoff or moved to table's dead list past
+// batchMaxAttempts. Must be called with m.mu held.
+func (m *MemoryManager) resolveLocked(ev *BatchEvent, ackErr error) {
+	if ackErr == nil {
+		return
+	}
+
+	ev.Attempts++
+	ev.LastError = ackErr.Error()
+	if ev.Attempts >= batchMaxAttempts {
+		m.dead[ev.Table] = append(m.dead[ev.Table], ev)
+		return
+	}
+	ev.NextAttemptAt = time.Now().Add(batchBackoff(ev.Attempts))
+	heap.Push(m.queueForLocked(ev.Table), ev)
+}
+
+// expireBatchLeases reclaims any lease Dequeue handed out whose worker
+// never called ack before it expired, treating it the same as an ack
+// with an error: retried with backoff, or dead-lettered past
+// batchMaxAttempts.
+func (m *MemoryManager) expireBatchLeases() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, leased := range m.leases {
+		if now.After(leased.expiresAt) {
+			delete(m.leases, id)
+			m.resolveLocked(leased.event, errLeaseExpired)
+		}
+	}
+}
+
+var errLeaseExpired = errBatchLeaseExpired{}
+
+// errBatchLeaseExpired is LastError's value on a BatchEvent the janitor
+// reclaimed because its worker never called ack in time.
+type errBatchLeaseExpired struct{}
+
+func (errBatchLeaseExpired) Error() string { return "batch lease expired before ack" }
+
+// ListDead returns table's dead-lettered events: those that failed
+// batchMaxAttempts times (whether via ack or an expired lease) without
+// ever succeeding.
+func (m *MemoryManager) ListDead(table string) ([]*BatchEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dead := m.dead[table]
+	out := make([]*BatchEvent, len(dead))
+	copy(out, dead)
+	return out, nil
+}
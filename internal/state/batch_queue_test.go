@@ -0,0 +1,91 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryManager_BatchQueue(t *testing.T) {
+	manager := NewMemoryManager().(*MemoryManager)
+	defer manager.Close()
+
+	table := "batch_table"
+	if err := manager.Enqueue(&BatchEvent{Table: table, Offset: 100, Rows: 50}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Nothing queued for a different table.
+	ev, ack, err := manager.Dequeue(ctx, "other_table", time.Second)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if ev != nil {
+		t.Errorf("Expected no event for other_table, got %+v", ev)
+	}
+
+	ev, ack, err = manager.Dequeue(ctx, table, time.Second)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if ev == nil {
+		t.Fatal("Expected an event, got nil")
+	}
+	if ev.Offset != 100 || ev.Rows != 50 {
+		t.Errorf("Expected Offset=100 Rows=50, got Offset=%d Rows=%d", ev.Offset, ev.Rows)
+	}
+
+	// Failing the batch should requeue it with backoff rather than drop it.
+	if err := ack(errors.New("transient failure")); err != nil {
+		t.Errorf("ack failed: %v", err)
+	}
+
+	ev, _, err = manager.Dequeue(ctx, table, time.Second)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if ev != nil {
+		t.Error("Expected the retried event to not be ready yet (backoff hasn't elapsed)")
+	}
+}
+
+func TestMemoryManager_BatchQueueDeadLetter(t *testing.T) {
+	manager := NewMemoryManager().(*MemoryManager)
+	defer manager.Close()
+
+	table := "dead_letter_table"
+	if err := manager.Enqueue(&BatchEvent{Table: table}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < batchMaxAttempts; i++ {
+		ev, ack, err := manager.Dequeue(ctx, table, time.Second)
+		if err != nil {
+			t.Fatalf("Dequeue failed on attempt %d: %v", i, err)
+		}
+		if ev == nil {
+			t.Fatalf("Expected an event on attempt %d, got nil", i)
+		}
+		if err := ack(errors.New("still failing")); err != nil {
+			t.Errorf("ack failed: %v", err)
+		}
+		// Force the next attempt to be immediately eligible instead of
+		// waiting out the backoff.
+		ev.NextAttemptAt = time.Time{}
+	}
+
+	dead, err := manager.ListDead(table)
+	if err != nil {
+		t.Fatalf("ListDead failed: %v", err)
+	}
+	if len(dead) != 1 {
+		t.Fatalf("Expected 1 dead-lettered event, got %d", len(dead))
+	}
+	if dead[0].Attempts != batchMaxAttempts {
+		t.Errorf("Expected Attempts=%d, got %d", batchMaxAttempts, dead[0].Attempts)
+	}
+}
@@ -0,0 +1,402 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltStatesBucket   = []byte("states")
+	boltManifestBucket = []byte("manifests")
+	boltLocksBucket    = []byte("locks")
+)
+
+// BoltManager implements the Manager interface against a local BoltDB
+// (bbolt) file, for single-host deployments that want crash-safe state
+// without standing up Redis or Postgres. bbolt itself takes an exclusive
+// file lock on Open, so only one process can hold the database open at a
+// time; LockState's TTL-leased ownership record on top of that is for
+// coordinating goroutines/workers *within* that one process (the same
+// work-stealing use internal/extractor's Coordinator makes of every other
+// backend's LockState), not across hosts the way Redis/Postgres/etcd can.
+type BoltManager struct {
+	db      *bolt.DB
+	ownerID string
+	hub     *watchHub
+}
+
+// boltLock is the JSON record stored under boltLocksBucket for one jobID.
+type boltLock struct {
+	Owner     string
+	ExpiresAt time.Time
+}
+
+// NewBoltManager opens (creating if necessary) a BoltDB file at path and
+// returns a BoltManager. ownerID identifies this process's leases.
+func NewBoltManager(path, ownerID string) (Manager, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltStatesBucket, boltManifestBucket, boltLocksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize bolt buckets: %v", err)
+	}
+
+	return &BoltManager{db: db, ownerID: ownerID, hub: newWatchHub()}, nil
+}
+
+// Watch streams StateEvents for changes this BoltManager applies. It's
+// backed by an in-process watchHub, not anything reading the BoltDB file
+// itself - the honest choice here, not merely the convenient one, since
+// bbolt's own exclusive file lock already limits a given database to one
+// process at a time, so there's no cross-process state this couldn't see.
+func (m *BoltManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	return m.hub.watch(ctx, filter)
+}
+
+func (m *BoltManager) GetState(ctx context.Context, table string) (*State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var s *State
+	err := m.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltStatesBucket).Get([]byte(table))
+		if data == nil {
+			return nil
+		}
+		s = &State{}
+		return json.Unmarshal(data, s)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %v", err)
+	}
+	return s, nil
+}
+
+func (m *BoltManager) saveState(s *State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+	return m.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltStatesBucket).Put([]byte(s.Table), data)
+	})
+}
+
+func (m *BoltManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	previous := snapshotState(s)
+	s.ProcessedRows = processedRows
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) CreateState(ctx context.Context, s *State) error {
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventCreated, s.Table, nil, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) DeleteState(ctx context.Context, jobID string) error {
+	previous, err := m.GetState(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if err := m.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltStatesBucket).Delete([]byte(jobID))
+	}); err != nil {
+		return err
+	}
+	m.hub.publish(EventDeleted, jobID, previous, nil)
+	return nil
+}
+
+func (m *BoltManager) ListStates(ctx context.Context) ([]*State, error) {
+	var states []*State
+	err := m.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltStatesBucket).ForEach(func(k, v []byte) error {
+			var s State
+			if err := json.Unmarshal(v, &s); err != nil {
+				return nil // Skip invalid states
+			}
+			states = append(states, &s)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %v", err)
+	}
+	return states, nil
+}
+
+// LockState acquires, or renews, a leader-election style lease on jobID,
+// recorded as a boltLock under boltLocksBucket, the same SETNX-with-TTL
+// semantics RedisManager implements with SET NX PX, evaluated inside one
+// bbolt read-write transaction instead of a Lua script.
+func (m *BoltManager) LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	var acquired bool
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltLocksBucket)
+		data := bucket.Get([]byte(jobID))
+
+		var existing boltLock
+		if data != nil {
+			if err := json.Unmarshal(data, &existing); err != nil {
+				return fmt.Errorf("failed to unmarshal lock: %v", err)
+			}
+			if time.Now().Before(existing.ExpiresAt) && existing.Owner != m.ownerID {
+				acquired = false
+				return nil
+			}
+		}
+
+		lock := boltLock{Owner: m.ownerID, ExpiresAt: time.Now().Add(duration)}
+		lockData, err := json.Marshal(lock)
+		if err != nil {
+			return fmt.Errorf("failed to marshal lock: %v", err)
+		}
+		if err := bucket.Put([]byte(jobID), lockData); err != nil {
+			return err
+		}
+		acquired = true
+		return nil
+	})
+	if err == nil && acquired {
+		m.hub.publish(EventLocked, jobID, nil, nil)
+	}
+	return acquired, err
+}
+
+func (m *BoltManager) UnlockState(ctx context.Context, jobID string) error {
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltLocksBucket)
+		data := bucket.Get([]byte(jobID))
+		if data == nil {
+			return nil
+		}
+		var lock boltLock
+		if err := json.Unmarshal(data, &lock); err != nil {
+			return fmt.Errorf("failed to unmarshal lock: %v", err)
+		}
+		if lock.Owner != m.ownerID {
+			// A different owner's lease; nothing for us to release.
+			return nil
+		}
+		return bucket.Delete([]byte(jobID))
+	})
+	if err == nil {
+		m.hub.publish(EventUnlocked, jobID, nil, nil)
+	}
+	return err
+}
+
+// RenewLock extends jobID's lease, if m.ownerID still holds it, keeping
+// the duration it was most recently acquired/renewed with.
+func (m *BoltManager) RenewLock(ctx context.Context, jobID string) error {
+	return m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltLocksBucket)
+		data := bucket.Get([]byte(jobID))
+		if data == nil {
+			return fmt.Errorf("no lock found for job ID: %s", jobID)
+		}
+		var lock boltLock
+		if err := json.Unmarshal(data, &lock); err != nil {
+			return fmt.Errorf("failed to unmarshal lock: %v", err)
+		}
+		if lock.Owner != m.ownerID {
+			return fmt.Errorf("lock for job ID %s is held by a different owner", jobID)
+		}
+		duration := time.Until(lock.ExpiresAt)
+		lock.ExpiresAt = time.Now().Add(duration)
+		lockData, err := json.Marshal(lock)
+		if err != nil {
+			return fmt.Errorf("failed to marshal lock: %v", err)
+		}
+		return bucket.Put([]byte(jobID), lockData)
+	})
+}
+
+// LockStatus reports jobID's current lease holder and expiry.
+func (m *BoltManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	var holder string
+	var expiresAt time.Time
+	err := m.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltLocksBucket).Get([]byte(jobID))
+		if data == nil {
+			return fmt.Errorf("no lock found for job ID: %s", jobID)
+		}
+		var lock boltLock
+		if err := json.Unmarshal(data, &lock); err != nil {
+			return fmt.Errorf("failed to unmarshal lock: %v", err)
+		}
+		holder = lock.Owner
+		expiresAt = lock.ExpiresAt
+		return nil
+	})
+	return holder, expiresAt, err
+}
+
+func (m *BoltManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	previous := snapshotState(s)
+	s.QueryID = queryID
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	previous := snapshotState(s)
+	s.LastKey = key
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	previous := snapshotState(s)
+	s.CDCPosition = position
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table}
+	}
+	previous := snapshotState(s)
+	s.Status = status
+	s.Error = errMsg
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+	previous := snapshotState(s)
+	s.Batches = append(s.Batches, bc)
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (m *BoltManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	previous := snapshotState(s)
+	s.SchemaHash = hash
+	s.SchemaColumns = columns
+	s.LastUpdated = time.Now()
+	if err := m.saveState(s); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(s))
+	return nil
+}
+
+func (m *BoltManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+	return m.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltManifestBucket).Put([]byte(table), data)
+	})
+}
+
+func (m *BoltManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	var manifest *Manifest
+	err := m.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltManifestBucket).Get([]byte(table))
+		if data == nil {
+			return fmt.Errorf("no manifest found for table: %s", table)
+		}
+		manifest = &Manifest{}
+		return json.Unmarshal(data, manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
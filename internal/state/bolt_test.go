@@ -0,0 +1,92 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBoltManager(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	manager, err := NewBoltManager(dbPath, "test-owner")
+	if err != nil {
+		t.Fatalf("Failed to create bolt manager: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("Basic Operations", func(t *testing.T) {
+		s := &State{
+			Table:       "test_table",
+			LastUpdated: time.Now(),
+			Status:      "running",
+		}
+		if err := manager.CreateState(ctx, s); err != nil {
+			t.Fatalf("Failed to create state: %v", err)
+		}
+
+		got, err := manager.GetState(ctx, "test_table")
+		if err != nil {
+			t.Fatalf("Failed to get state: %v", err)
+		}
+		if got.Table != s.Table {
+			t.Errorf("Expected table %s, got %s", s.Table, got.Table)
+		}
+
+		if err := manager.UpdateState(ctx, "test_table", 100); err != nil {
+			t.Fatalf("Failed to update state: %v", err)
+		}
+		got, err = manager.GetState(ctx, "test_table")
+		if err != nil {
+			t.Fatalf("Failed to get updated state: %v", err)
+		}
+		if got.ProcessedRows != 100 {
+			t.Errorf("Expected 100 processed rows, got %d", got.ProcessedRows)
+		}
+
+		if err := manager.DeleteState(ctx, "test_table"); err != nil {
+			t.Fatalf("Failed to delete state: %v", err)
+		}
+		got, err = manager.GetState(ctx, "test_table")
+		if err != nil {
+			t.Fatalf("Unexpected error getting deleted state: %v", err)
+		}
+		if got != nil {
+			t.Error("Expected nil state after delete")
+		}
+	})
+
+	t.Run("Locking", func(t *testing.T) {
+		ok, err := manager.LockState(ctx, "job-1", time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to lock state: %v", err)
+		}
+		if !ok {
+			t.Fatal("Expected to acquire lock")
+		}
+
+		if err := manager.RenewLock(ctx, "job-1"); err != nil {
+			t.Fatalf("Failed to renew lock: %v", err)
+		}
+
+		holder, _, err := manager.LockStatus(ctx, "job-1")
+		if err != nil {
+			t.Fatalf("Failed to read lock status: %v", err)
+		}
+		if holder != "test-owner" {
+			t.Errorf("Expected holder test-owner, got %s", holder)
+		}
+
+		if err := manager.UnlockState(ctx, "job-1"); err != nil {
+			t.Fatalf("Failed to unlock state: %v", err)
+		}
+
+		ok, err = manager.LockState(ctx, "job-1", time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to re-lock state: %v", err)
+		}
+		if !ok {
+			t.Fatal("Expected to re-acquire lock after unlock")
+		}
+	})
+}
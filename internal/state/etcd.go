@@ -0,0 +1,591 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdManager implements the Manager interface against etcd, for
+// deployments that already run etcd for cluster coordination and want
+// LockState backed by etcd's own lease-and-fencing primitives rather than
+// a TTL convention layered on top of a plain key-value store the way
+// RedisManager and BoltManager do. Every key is namespaced under
+// EtcdManager.namespace so one etcd cluster can be shared by multiple
+// sqlextract deployments without their states/locks colliding.
+type EtcdManager struct {
+	client    *clientv3.Client
+	namespace string
+	ownerID   string
+}
+
+// NewEtcdManager dials the given etcd endpoints and returns an
+// EtcdManager whose keys are namespaced under namespace. ownerID
+// identifies this process's leases, the same role it plays for
+// RedisManager and BoltManager.
+func NewEtcdManager(endpoints []string, namespace, ownerID string) (Manager, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to etcd: %v", err)
+	}
+	return &EtcdManager{client: client, namespace: namespace, ownerID: ownerID}, nil
+}
+
+func (m *EtcdManager) stateKey(table string) string {
+	return fmt.Sprintf("%s/state/%s", m.namespace, table)
+}
+
+func (m *EtcdManager) manifestKey(table string) string {
+	return fmt.Sprintf("%s/manifest/%s", m.namespace, table)
+}
+
+func (m *EtcdManager) lockKey(jobID string) string {
+	return fmt.Sprintf("%s/lock/%s", m.namespace, jobID)
+}
+
+func (m *EtcdManager) ownerKey(table string) string {
+	return fmt.Sprintf("%s/owner/%s", m.namespace, table)
+}
+
+// etcdLock is the JSON value stored under lockKey, recording who holds
+// the lease and which etcd lease ID it's tied to so RenewLock can keep
+// that same lease alive instead of re-granting a new one.
+type etcdLock struct {
+	Owner   string
+	LeaseID int64
+}
+
+func (m *EtcdManager) GetState(ctx context.Context, table string) (*State, error) {
+	resp, err := m.client.Get(ctx, m.stateKey(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	var s State
+	if err := json.Unmarshal(resp.Kvs[0].Value, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %v", err)
+	}
+	return &s, nil
+}
+
+func (m *EtcdManager) saveState(ctx context.Context, s *State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+	if _, err := m.client.Put(ctx, m.stateKey(s.Table), string(data)); err != nil {
+		return fmt.Errorf("failed to save state: %v", err)
+	}
+	return nil
+}
+
+func (m *EtcdManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.ProcessedRows = processedRows
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) CreateState(ctx context.Context, s *State) error {
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) DeleteState(ctx context.Context, jobID string) error {
+	if _, err := m.client.Delete(ctx, m.stateKey(jobID)); err != nil {
+		return fmt.Errorf("failed to delete state: %v", err)
+	}
+	return nil
+}
+
+func (m *EtcdManager) ListStates(ctx context.Context) ([]*State, error) {
+	resp, err := m.client.Get(ctx, m.namespace+"/state/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %v", err)
+	}
+	var states []*State
+	for _, kv := range resp.Kvs {
+		var s State
+		if err := json.Unmarshal(kv.Value, &s); err != nil {
+			continue // Skip invalid states
+		}
+		states = append(states, &s)
+	}
+	return states, nil
+}
+
+// Watch streams StateEvents from etcd's own native clientv3.Watch on
+// this namespace's state prefix, so it reports changes any replica
+// makes, not just this one.
+func (m *EtcdManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	prefix := m.namespace + "/state/"
+	watchCh := m.client.Watch(ctx, prefix, clientv3.WithPrefix())
+
+	out := make(chan StateEvent, watchHubBuffer)
+
+	go func() {
+		defer close(out)
+
+		var seq uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resp, ok := <-watchCh:
+				if !ok {
+					return
+				}
+				if resp.Err() != nil {
+					return
+				}
+
+				for _, wev := range resp.Events {
+					table := strings.TrimPrefix(string(wev.Kv.Key), prefix)
+
+					var kind EventKind
+					var current *State
+					switch wev.Type {
+					case clientv3.EventTypePut:
+						if wev.IsCreate() {
+							kind = EventCreated
+						} else {
+							kind = EventUpdated
+						}
+						var s State
+						if err := json.Unmarshal(wev.Kv.Value, &s); err == nil {
+							current = &s
+						}
+					case clientv3.EventTypeDelete:
+						kind = EventDeleted
+					default:
+						continue
+					}
+
+					event := StateEvent{Table: table, Kind: kind, Current: current}
+					if !filter.matches(event) {
+						continue
+					}
+					seq++
+					event.Seq = seq
+
+					select {
+					case out <- event:
+					default:
+						// Subscriber's fallen behind; drop rather than
+						// block the watch-reading goroutine.
+					}
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// LockState acquires, or renews, a lease on jobID backed by a real etcd
+// lease (client.Grant), transactionally put only if the key doesn't
+// already exist (the etcd analogue of Redis's SET NX), so only one
+// replica holds it at a time.
+func (m *EtcdManager) LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	key := m.lockKey(jobID)
+
+	existing, err := m.client.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read lock: %v", err)
+	}
+	if len(existing.Kvs) > 0 {
+		var lock etcdLock
+		if err := json.Unmarshal(existing.Kvs[0].Value, &lock); err != nil {
+			return false, fmt.Errorf("failed to unmarshal lock: %v", err)
+		}
+		if lock.Owner != m.ownerID {
+			return false, nil
+		}
+		// Already ours; fall through to re-grant a fresh lease below so
+		// this call also serves as a renewal.
+	}
+
+	lease, err := m.client.Grant(ctx, int64(duration.Seconds()))
+	if err != nil {
+		return false, fmt.Errorf("failed to grant lease: %v", err)
+	}
+	data, err := json.Marshal(etcdLock{Owner: m.ownerID, LeaseID: int64(lease.ID)})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal lock: %v", err)
+	}
+
+	txn := m.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(data), clientv3.WithLease(lease.ID)))
+	resp, err := txn.Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	if resp.Succeeded {
+		return true, nil
+	}
+
+	// Key already existed when the transaction ran; re-check ownership
+	// (it may have just been released) rather than assume contention.
+	existing, err = m.client.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read lock: %v", err)
+	}
+	if len(existing.Kvs) == 0 {
+		return false, nil // Released mid-check; let the caller retry.
+	}
+	var lock etcdLock
+	if err := json.Unmarshal(existing.Kvs[0].Value, &lock); err != nil {
+		return false, fmt.Errorf("failed to unmarshal lock: %v", err)
+	}
+	if lock.Owner != m.ownerID {
+		return false, nil
+	}
+	if _, err := m.client.Put(ctx, key, string(data), clientv3.WithLease(lease.ID)); err != nil {
+		return false, fmt.Errorf("failed to renew lock: %v", err)
+	}
+	return true, nil
+}
+
+func (m *EtcdManager) UnlockState(ctx context.Context, jobID string) error {
+	key := m.lockKey(jobID)
+	resp, err := m.client.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read lock: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	var lock etcdLock
+	if err := json.Unmarshal(resp.Kvs[0].Value, &lock); err != nil {
+		return fmt.Errorf("failed to unmarshal lock: %v", err)
+	}
+	if lock.Owner != m.ownerID {
+		// A different owner's lease; nothing for us to release.
+		return nil
+	}
+	if _, err := m.client.Revoke(ctx, clientv3.LeaseID(lock.LeaseID)); err != nil {
+		return fmt.Errorf("failed to release lock: %v", err)
+	}
+	return nil
+}
+
+// RenewLock extends jobID's lease, if m.ownerID still holds it, by
+// keeping its existing etcd lease alive (KeepAliveOnce) rather than
+// granting a new one, so the lock key (which is tied to that lease) isn't
+// briefly deleted and recreated.
+func (m *EtcdManager) RenewLock(ctx context.Context, jobID string) error {
+	resp, err := m.client.Get(ctx, m.lockKey(jobID))
+	if err != nil {
+		return fmt.Errorf("failed to read lock: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	var lock etcdLock
+	if err := json.Unmarshal(resp.Kvs[0].Value, &lock); err != nil {
+		return fmt.Errorf("failed to unmarshal lock: %v", err)
+	}
+	if lock.Owner != m.ownerID {
+		return fmt.Errorf("lock for job ID %s is held by a different owner", jobID)
+	}
+	if _, err := m.client.KeepAliveOnce(ctx, clientv3.LeaseID(lock.LeaseID)); err != nil {
+		return fmt.Errorf("failed to renew lock: %v", err)
+	}
+	return nil
+}
+
+// LockStatus reports jobID's current lease holder and expiry, derived
+// from the remaining TTL etcd reports for the lease backing it.
+func (m *EtcdManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	resp, err := m.client.Get(ctx, m.lockKey(jobID))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read lock: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	var lock etcdLock
+	if err := json.Unmarshal(resp.Kvs[0].Value, &lock); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to unmarshal lock: %v", err)
+	}
+	ttlResp, err := m.client.TimeToLive(ctx, clientv3.LeaseID(lock.LeaseID))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read lease TTL: %v", err)
+	}
+	if ttlResp.TTL < 0 {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	return lock.Owner, time.Now().Add(time.Duration(ttlResp.TTL) * time.Second), nil
+}
+
+func (m *EtcdManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.QueryID = queryID
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.LastKey = key
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.CDCPosition = position
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table}
+	}
+	s.Status = status
+	s.Error = errMsg
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+	s.Batches = append(s.Batches, bc)
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (m *EtcdManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.SchemaHash = hash
+	s.SchemaColumns = columns
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *EtcdManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+	if _, err := m.client.Put(ctx, m.manifestKey(table), string(data)); err != nil {
+		return fmt.Errorf("failed to save manifest: %v", err)
+	}
+	return nil
+}
+
+func (m *EtcdManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	resp, err := m.client.Get(ctx, m.manifestKey(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("no manifest found for table: %s", table)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(resp.Kvs[0].Value, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+	}
+	return &manifest, nil
+}
+
+// etcdOwner is the JSON value stored under ownerKey, recording who holds
+// a table and which etcd lease ID its claim is bound to, the same
+// pattern etcdLock uses for jobID leases.
+type etcdOwner struct {
+	Owner   string
+	LeaseID int64
+}
+
+// CampaignOwner makes workerID compete for ownership of every table
+// under this namespace, using a real etcd lease per table instead of a
+// heartbeat timestamp to detect staleness: a table is claimed by a
+// transactional put bound to a lease of ownerStaleFactor*lease seconds,
+// so an owner that stops renewing it (e.g. it crashed) has etcd itself
+// expire the claim rather than requiring another worker to notice a
+// stale heartbeat. A held table's lease is kept alive every
+// lease/campaignSweepDivisor. The returned channel receives the full set
+// of tables workerID owns each time it changes; resign revokes every
+// lease this call holds, releasing those tables immediately.
+func (m *EtcdManager) CampaignOwner(ctx context.Context, workerID string, lease time.Duration) (<-chan []string, func(), error) {
+	if lease <= 0 {
+		return nil, nil, fmt.Errorf("state: lease must be positive")
+	}
+
+	out := make(chan []string, 1)
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+	owned := make(map[string]clientv3.LeaseID)
+	var ownedMu sync.Mutex
+
+	resign := func() {
+		stopOnce.Do(func() {
+			close(stopCh)
+			ownedMu.Lock()
+			for _, leaseID := range owned {
+				m.client.Revoke(context.Background(), leaseID)
+			}
+			ownedMu.Unlock()
+		})
+	}
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(lease / campaignSweepDivisor)
+		defer ticker.Stop()
+
+		var current []string
+		sweep := func() {
+			next, err := m.campaignSweep(ctx, workerID, lease, owned, &ownedMu)
+			if err != nil || equalSortedStrings(current, next) {
+				return
+			}
+			current = next
+			select {
+			case out <- current:
+			case <-stopCh:
+			case <-ctx.Done():
+			}
+		}
+
+		sweep()
+		for {
+			select {
+			case <-ctx.Done():
+				resign()
+				return
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+
+	return out, resign, nil
+}
+
+// campaignSweep claims every table under this namespace with no live
+// owner key - either never claimed, or its previous owner's lease
+// already expired, which etcd enforces natively instead of requiring a
+// heartbeat-timestamp comparison - keeps owned's leases alive, and
+// returns the sorted set of tables workerID owns afterward.
+func (m *EtcdManager) campaignSweep(ctx context.Context, workerID string, lease time.Duration, owned map[string]clientv3.LeaseID, ownedMu *sync.Mutex) ([]string, error) {
+	resp, err := m.client.Get(ctx, m.namespace+"/state/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %v", err)
+	}
+
+	ownedMu.Lock()
+	defer ownedMu.Unlock()
+
+	for _, kv := range resp.Kvs {
+		table := strings.TrimPrefix(string(kv.Key), m.namespace+"/state/")
+		if leaseID, ok := owned[table]; ok {
+			if _, err := m.client.KeepAliveOnce(ctx, leaseID); err != nil {
+				delete(owned, table) // Lease died; the next sweep will try to re-claim it.
+			}
+			continue
+		}
+
+		key := m.ownerKey(table)
+		grant, err := m.client.Grant(ctx, int64(lease.Seconds()*ownerStaleFactor))
+		if err != nil {
+			continue
+		}
+		data, err := json.Marshal(etcdOwner{Owner: workerID, LeaseID: int64(grant.ID)})
+		if err != nil {
+			continue
+		}
+
+		txn := m.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+			Then(clientv3.OpPut(key, string(data), clientv3.WithLease(grant.ID)))
+		txnResp, err := txn.Commit()
+		if err != nil {
+			continue
+		}
+		if txnResp.Succeeded {
+			owned[table] = grant.ID
+		} else {
+			m.client.Revoke(ctx, grant.ID) // Lost the race to another worker; don't leak the lease.
+		}
+	}
+
+	tables := make([]string, 0, len(owned))
+	for table := range owned {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// ParseEndpoints splits a comma-separated etcd endpoint list, the form a
+// --state-dsn value for backend "etcd" is expected to take (e.g.
+// "etcd1:2379,etcd2:2379,etcd3:2379"), mirroring how -redis-addr takes a
+// single "host:port" value.
+func ParseEndpoints(dsn string) []string {
+	parts := strings.Split(dsn, ",")
+	endpoints := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			endpoints = append(endpoints, p)
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,182 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLockFenced is returned by UnlockStateFenced when the fencing token
+// presented no longer matches the current holder of that table's lock -
+// typically because the caller's lease already expired and
+// LockStateFenced has since handed the lock to another waiter. Rejecting
+// the call instead of applying it keeps a resumed zombie writer from
+// releasing a lock a newer holder already took out.
+var ErrLockFenced = errors.New("state: fencing token rejected, lock no longer held")
+
+// FencedLocker is implemented by Managers that can hand out a fencing
+// token alongside a lock, so a caller renewing or releasing it can be
+// rejected if its lease has since expired and the lock moved on to
+// another holder. Not every backend can offer this on top of LockState's
+// plain bool, so it's a separate, optional interface rather than a
+// change to LockState's signature.
+type FencedLocker interface {
+	// LockStateFenced blocks until table's lock is free and this call
+	// reaches the head of its FIFO wait queue, or ctx is done, returning
+	// a fencing token that must be presented to UnlockStateFenced for
+	// the duration of the lease.
+	LockStateFenced(ctx context.Context, table string, duration time.Duration) (token uint64, err error)
+	// UnlockStateFenced releases table's lock if token still matches its
+	// current holder, handing it to the next FIFO waiter. A stale token
+	// returns ErrLockFenced rather than releasing someone else's lock.
+	UnlockStateFenced(ctx context.Context, table string, token uint64) error
+}
+
+// tableLock records the current fencing token and expiry for one
+// table's fenced lock.
+type tableLock struct {
+	token     uint64
+	expiresAt time.Time
+}
+
+// lockWaiter is one LockStateFenced call queued for table, woken (via
+// ready) once it reaches the head of the FIFO queue and the lock is
+// free.
+type lockWaiter struct {
+	ready chan struct{}
+}
+
+// expireFenceLocks releases any fenced lock whose lease has passed
+// expiresAt without an UnlockStateFenced call (e.g. its holder
+// crashed), and wakes that table's next FIFO waiter, if any.
+func (m *MemoryManager) expireFenceLocks() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for table, lock := range m.fenceLocks {
+		if now.After(lock.expiresAt) {
+			delete(m.fenceLocks, table)
+			m.wakeHeadLocked(table)
+		}
+	}
+}
+
+// wakeHeadLocked signals the waiter at the head of table's queue, if
+// any, that it may attempt to acquire the lock. Must be called with
+// m.mu held.
+func (m *MemoryManager) wakeHeadLocked(table string) {
+	queue := m.waiters[table]
+	if len(queue) == 0 {
+		return
+	}
+	select {
+	case queue[0].ready <- struct{}{}:
+	default:
+	}
+}
+
+// acquireFenceLocked grants table's fenced lock to whoever is calling
+// (the head waiter, or a caller that found it free with nobody queued
+// ahead of it), minting a new fencing token. Must be called with m.mu
+// held. A journal append failure here is not propagated to the caller -
+// doing so would mean LockStateFenced's blocking FIFO handoff could
+// itself fail after a waiter was already woken, which is harder to
+// recover from correctly than accepting that a crash between acquiring
+// the lock and the next successful append may replay as a slightly
+// stale lock state.
+func (m *MemoryManager) acquireFenceLocked(table string, duration time.Duration) uint64 {
+	m.nextToken++
+	token := m.nextToken
+	expiresAt := time.Now().Add(duration)
+	m.fenceLocks[table] = &tableLock{token: token, expiresAt: expiresAt}
+	if m.journal != nil {
+		m.journal.append(opLockState, lockStatePayload{Table: table, Token: token, ExpiresAt: expiresAt})
+	}
+	return token
+}
+
+// LockStateFenced blocks until table's lock is free and this call
+// reaches the head of its FIFO wait queue, or ctx is done, returning a
+// fencing token that must be presented to UnlockStateFenced for the
+// duration of the lease. Waiters are woken in arrival order rather than
+// racing each other for the lock once it frees up.
+func (m *MemoryManager) LockStateFenced(ctx context.Context, table string, duration time.Duration) (uint64, error) {
+	m.mu.Lock()
+	w := &lockWaiter{ready: make(chan struct{}, 1)}
+	queue := m.waiters[table]
+	wasEmpty := len(queue) == 0
+	m.waiters[table] = append(queue, w)
+
+	lock, locked := m.fenceLocks[table]
+	free := !locked || time.Now().After(lock.expiresAt)
+	if wasEmpty && free {
+		token := m.acquireFenceLocked(table, duration)
+		m.waiters[table] = m.waiters[table][1:]
+		m.mu.Unlock()
+		m.hub.publish(EventLocked, table, nil, nil)
+		return token, nil
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		m.mu.Lock()
+		token := m.acquireFenceLocked(table, duration)
+		m.waiters[table] = m.waiters[table][1:]
+		m.mu.Unlock()
+		m.hub.publish(EventLocked, table, nil, nil)
+		return token, nil
+	case <-ctx.Done():
+		m.mu.Lock()
+		m.removeWaiterLocked(table, w)
+		m.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// removeWaiterLocked drops w from table's wait queue (e.g. because its
+// ctx was canceled before its turn came), waking the new head if w had
+// been it, since the lock may already be free for them. Must be called
+// with m.mu held.
+func (m *MemoryManager) removeWaiterLocked(table string, w *lockWaiter) {
+	queue := m.waiters[table]
+	wasHead := len(queue) > 0 && queue[0] == w
+	for i, q := range queue {
+		if q == w {
+			m.waiters[table] = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if wasHead {
+		m.wakeHeadLocked(table)
+	}
+}
+
+// UnlockStateFenced releases table's lock if token still matches its
+// current holder, handing it to the next FIFO waiter. A stale token (the
+// lease already expired and was reassigned, or this caller never held
+// it) returns ErrLockFenced rather than releasing someone else's lock.
+func (m *MemoryManager) UnlockStateFenced(ctx context.Context, table string, token uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock, locked := m.fenceLocks[table]
+	if !locked || lock.token != token {
+		return ErrLockFenced
+	}
+
+	delete(m.fenceLocks, table)
+	m.wakeHeadLocked(table)
+	m.hub.publish(EventUnlocked, table, nil, nil)
+	if m.journal != nil {
+		if err := m.journal.append(opUnlockState, unlockStatePayload{Table: table}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryManager_FencedLockConcurrent(t *testing.T) {
+	manager := NewMemoryManager().(*MemoryManager)
+	defer manager.Close()
+	done := make(chan bool)
+
+	table := "test_table"
+
+	// Start multiple goroutines contending for the same table's fenced
+	// lock, each taking it and releasing it, to exercise the FIFO wait
+	// queue under contention.
+	for i := 0; i < 10; i++ {
+		go func(id int) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			for j := 0; j < 20; j++ {
+				token, err := manager.LockStateFenced(ctx, table, time.Second)
+				if err != nil {
+					t.Errorf("LockStateFenced failed in goroutine %d: %v", id, err)
+					continue
+				}
+				if err := manager.UnlockStateFenced(ctx, table, token); err != nil {
+					t.Errorf("UnlockStateFenced failed in goroutine %d: %v", id, err)
+				}
+			}
+
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+}
+
+func TestMemoryManager_FencedLockStaleToken(t *testing.T) {
+	manager := NewMemoryManager().(*MemoryManager)
+	defer manager.Close()
+
+	table := "fenced_table"
+	ctx := context.Background()
+
+	token, err := manager.LockStateFenced(ctx, table, time.Minute)
+	if err != nil {
+		t.Fatalf("LockStateFenced failed: %v", err)
+	}
+
+	if err := manager.UnlockStateFenced(ctx, table, token+1); err != ErrLockFenced {
+		t.Errorf("Expected ErrLockFenced for a stale token, got %v", err)
+	}
+
+	if err := manager.UnlockStateFenced(ctx, table, token); err != nil {
+		t.Fatalf("UnlockStateFenced failed: %v", err)
+	}
+
+	// The lock is free again; presenting the now-released token must be
+	// rejected rather than silently succeeding.
+	if err := manager.UnlockStateFenced(ctx, table, token); err != ErrLockFenced {
+		t.Errorf("Expected ErrLockFenced after unlock, got %v", err)
+	}
+}
+
+func TestMemoryManager_LockStateFencedContextCancel(t *testing.T) {
+	manager := NewMemoryManager().(*MemoryManager)
+	defer manager.Close()
+
+	table := "ctx_table"
+	ctx := context.Background()
+
+	token, err := manager.LockStateFenced(ctx, table, time.Hour)
+	if err != nil {
+		t.Fatalf("LockStateFenced failed: %v", err)
+	}
+	defer manager.UnlockStateFenced(ctx, table, token)
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_, err = manager.LockStateFenced(cancelCtx, table, time.Minute)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
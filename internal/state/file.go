@@ -1,6 +1,7 @@
 package state
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -12,17 +13,85 @@ import (
 // FileStateManager implements the Manager interface using file-based storage
 type FileStateManager struct {
 	baseDir string
+	ownerID string
 	mu      sync.RWMutex
+	hub     *watchHub
 }
 
-// NewFileStateManager creates a new file-based state manager
+// NewFileStateManager creates a new file-based state manager. ownerID is
+// recorded in each lock file and reported by LockStatus; it has no
+// bearing on LockState's contention logic, since a single baseDir is
+// normally owned by one process.
 func NewFileStateManager(baseDir string) Manager {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
 	return &FileStateManager{
 		baseDir: baseDir,
+		ownerID: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		hub:     newWatchHub(),
+	}
+}
+
+// Watch streams StateEvents for changes this FileStateManager applies.
+// Like MemoryManager, it's backed by an in-process watchHub rather than
+// anything that inspects the state directory itself, so it only sees
+// changes made through this Manager instance, not ones another process
+// makes to the same baseDir.
+func (m *FileStateManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	return m.hub.watch(ctx, filter)
+}
+
+// fileLock is the persisted form of a FileStateManager lease. Duration
+// is kept alongside ExpiresAt so RenewLock can extend the lease by the
+// same span LockState originally used for it.
+type fileLock struct {
+	Owner     string        `json:"owner"`
+	Duration  time.Duration `json:"duration"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+func (m *FileStateManager) lockPath(jobID string) string {
+	return filepath.Join(m.baseDir, fmt.Sprintf("%s.lock", jobID))
+}
+
+func (m *FileStateManager) readLock(jobID string) (*fileLock, error) {
+	data, err := os.ReadFile(m.lockPath(jobID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read lock file: %v", err)
+	}
+
+	var lock fileLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lock file: %v", err)
+	}
+	return &lock, nil
+}
+
+func (m *FileStateManager) writeLock(jobID string, lock *fileLock) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock: %v", err)
 	}
+	if err := os.WriteFile(m.lockPath(jobID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %v", err)
+	}
+	return nil
 }
 
-func (m *FileStateManager) GetState(table string) (*State, error) {
+// fileIO operations below don't accept a context, so every public method
+// checks ctx up front and bails out before touching disk if it's already
+// been canceled or timed out, instead of completing regardless.
+
+func (m *FileStateManager) GetState(ctx context.Context, table string) (*State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -43,11 +112,15 @@ func (m *FileStateManager) GetState(table string) (*State, error) {
 	return &state, nil
 }
 
-func (m *FileStateManager) UpdateState(table string, processedRows int64) error {
+func (m *FileStateManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	state, err := m.GetState(table)
+	state, err := m.GetState(ctx, table)
 	if err != nil {
 		return err
 	}
@@ -59,17 +132,26 @@ func (m *FileStateManager) UpdateState(table string, processedRows int64) error
 		}
 	}
 
+	previous := snapshotState(state)
 	state.ProcessedRows = processedRows
 	state.LastUpdated = time.Now()
 
-	return m.saveState(state)
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
 }
 
-func (m *FileStateManager) CreateState(state *State) error {
+func (m *FileStateManager) CreateState(ctx context.Context, state *State) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	existingState, err := m.GetState(state.Table)
+	existingState, err := m.GetState(ctx, state.Table)
 	if err != nil {
 		return err
 	}
@@ -77,14 +159,22 @@ func (m *FileStateManager) CreateState(state *State) error {
 		return fmt.Errorf("state already exists for table: %s", state.Table)
 	}
 
-	return m.saveState(state)
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventCreated, state.Table, nil, snapshotState(state))
+	return nil
 }
 
-func (m *FileStateManager) DeleteState(jobID string) error {
+func (m *FileStateManager) DeleteState(ctx context.Context, jobID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	states, err := m.ListStates()
+	states, err := m.ListStates(ctx)
 	if err != nil {
 		return err
 	}
@@ -95,6 +185,7 @@ func (m *FileStateManager) DeleteState(jobID string) error {
 			if err := os.Remove(stateFile); err != nil {
 				return fmt.Errorf("failed to delete state file: %v", err)
 			}
+			m.hub.publish(EventDeleted, state.Table, snapshotState(state), nil)
 			return nil
 		}
 	}
@@ -102,7 +193,11 @@ func (m *FileStateManager) DeleteState(jobID string) error {
 	return fmt.Errorf("state not found for job ID: %s", jobID)
 }
 
-func (m *FileStateManager) ListStates() ([]*State, error) {
+func (m *FileStateManager) ListStates(ctx context.Context) ([]*State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -130,47 +225,38 @@ func (m *FileStateManager) ListStates() ([]*State, error) {
 	return states, nil
 }
 
-func (m *FileStateManager) LockState(jobID string, duration time.Duration) (bool, error) {
+func (m *FileStateManager) LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	lockFile := filepath.Join(m.baseDir, fmt.Sprintf("%s.lock", jobID))
-	if _, err := os.Stat(lockFile); err == nil {
-		// Lock file exists, check if it's expired
-		data, err := os.ReadFile(lockFile)
-		if err != nil {
-			return false, fmt.Errorf("failed to read lock file: %v", err)
-		}
-
-		var lockTime time.Time
-		if err := json.Unmarshal(data, &lockTime); err != nil {
-			return false, fmt.Errorf("failed to unmarshal lock time: %v", err)
-		}
-
-		if lockTime.After(time.Now()) {
-			return false, nil
-		}
-	}
-
-	// Create or update lock file
-	lockTime := time.Now().Add(duration)
-	data, err := json.Marshal(lockTime)
+	lock, err := m.readLock(jobID)
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal lock time: %v", err)
+		return false, err
 	}
-
-	if err := os.WriteFile(lockFile, data, 0644); err != nil {
-		return false, fmt.Errorf("failed to write lock file: %v", err)
+	if lock != nil && lock.ExpiresAt.After(time.Now()) {
+		return false, nil
 	}
 
+	if err := m.writeLock(jobID, &fileLock{Owner: m.ownerID, Duration: duration, ExpiresAt: time.Now().Add(duration)}); err != nil {
+		return false, err
+	}
+	m.hub.publish(EventLocked, jobID, nil, nil)
 	return true, nil
 }
 
-func (m *FileStateManager) UnlockState(jobID string) error {
+func (m *FileStateManager) UnlockState(ctx context.Context, jobID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	lockFile := filepath.Join(m.baseDir, fmt.Sprintf("%s.lock", jobID))
+	lockFile := m.lockPath(jobID)
 	if _, err := os.Stat(lockFile); err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("no lock found for job ID: %s", jobID)
@@ -182,6 +268,266 @@ func (m *FileStateManager) UnlockState(jobID string) error {
 		return fmt.Errorf("failed to remove lock file: %v", err)
 	}
 
+	m.hub.publish(EventUnlocked, jobID, nil, nil)
+	return nil
+}
+
+// RenewLock extends jobID's lease by the same duration last passed to
+// LockState for it.
+func (m *FileStateManager) RenewLock(ctx context.Context, jobID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock, err := m.readLock(jobID)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+
+	return m.writeLock(jobID, &fileLock{Owner: lock.Owner, Duration: lock.Duration, ExpiresAt: time.Now().Add(lock.Duration)})
+}
+
+// LockStatus reports jobID's current lease holder and expiry.
+func (m *FileStateManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return "", time.Time{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lock, err := m.readLock(jobID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if lock == nil {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	return lock.Owner, lock.ExpiresAt, nil
+}
+
+// UpdateQueryID persists the in-flight async query ID for a table
+func (m *FileStateManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.QueryID = queryID
+	state.LastUpdated = time.Now()
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateLastKey persists the last key-column tuple emitted by a
+// keyset-paginated ExtractBatch for a table
+func (m *FileStateManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.LastKey = key
+	state.LastUpdated = time.Now()
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateCDCPosition persists the change-data-capture cursor consumed
+// through the most recent ExtractCDC call for table.
+func (m *FileStateManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.CDCPosition = position
+	state.LastUpdated = time.Now()
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateStatus persists status for table, along with errMsg when status
+// is "failed".
+func (m *FileStateManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.Status = status
+	state.Error = errMsg
+	state.LastUpdated = time.Now()
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// SaveManifest persists the manifest produced by the most recent
+// extraction of table as a <table>.manifest JSON file, overwriting any
+// manifest saved earlier.
+func (m *FileStateManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	manifestFile := filepath.Join(m.baseDir, fmt.Sprintf("%s.manifest", table))
+	if err := os.WriteFile(manifestFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest file: %v", err)
+	}
+
+	return nil
+}
+
+// GetManifest retrieves the manifest saved for table, or nil if none has
+// been saved yet.
+func (m *FileStateManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	manifestFile := filepath.Join(m.baseDir, fmt.Sprintf("%s.manifest", table))
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read manifest file: %v", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+	}
+
+	return &manifest, nil
+}
+
+// RecordBatch appends bc to table's Batches and persists the state file.
+func (m *FileStateManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.Batches = append(state.Batches, bc)
+	state.LastUpdated = time.Now()
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (m *FileStateManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.SchemaHash = hash
+	state.SchemaColumns = columns
+	state.LastUpdated = time.Now()
+	if err := m.saveState(state); err != nil {
+		return err
+	}
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
 	return nil
 }
 
@@ -0,0 +1,458 @@
+package state
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SyncMode controls how aggressively a journal flushes each appended
+// record to disk, trading durability against write throughput.
+type SyncMode struct {
+	kind     syncModeKind
+	interval time.Duration
+}
+
+type syncModeKind int
+
+const (
+	syncAlways syncModeKind = iota
+	syncInterval
+	syncNever
+)
+
+// SyncAlways fsyncs the journal after every appended record - the
+// safest, slowest mode, and JournalOptions's zero value.
+var SyncAlways = SyncMode{kind: syncAlways}
+
+// SyncNever never fsyncs the journal itself, relying on the OS to flush
+// it eventually. A process crash (not just the writing goroutine dying)
+// before that happens can lose the unflushed tail.
+var SyncNever = SyncMode{kind: syncNever}
+
+// SyncInterval fsyncs at most once every d, batching writes between
+// syncs instead of paying fsync's cost on every append.
+func SyncInterval(d time.Duration) SyncMode {
+	return SyncMode{kind: syncInterval, interval: d}
+}
+
+// JournalOptions configures NewMemoryManagerWithJournal.
+type JournalOptions struct {
+	// SyncMode controls durability vs. throughput for journal appends.
+	// The zero value behaves as SyncAlways.
+	SyncMode SyncMode
+	// MaxSize is the journal file size, in bytes, past which the
+	// background compactor snapshots state and truncates it. Zero
+	// disables compaction.
+	MaxSize int64
+}
+
+// journalOp tags what a journal record's payload represents, so replay
+// knows how to decode and apply it.
+type journalOp uint8
+
+const (
+	opCreateState journalOp = iota
+	opUpdateState
+	opDeleteState
+	opLockState
+	opUnlockState
+)
+
+type createStatePayload struct {
+	State *State
+}
+
+type updateStatePayload struct {
+	Table         string
+	ProcessedRows int64
+}
+
+type deleteStatePayload struct {
+	Table string
+}
+
+type lockStatePayload struct {
+	Table     string
+	Token     uint64
+	ExpiresAt time.Time
+}
+
+type unlockStatePayload struct {
+	Table string
+}
+
+// journalRecordHeaderSize is the on-disk size, in bytes, of a record's
+// seq+ts+op prefix, before its variable-length payload.
+const journalRecordHeaderSize = 8 + 8 + 1
+
+// journal is an append-only, length-prefixed, CRC32-checked log of the
+// mutations applied to a MemoryManager, letting it survive process
+// restarts without an external backend - the LevelDB journal/memdb
+// pattern, scoped down to sqlextract's tiny state set.
+type journal struct {
+	mu       sync.Mutex
+	file     *os.File
+	path     string
+	seq      uint64
+	size     int64
+	syncMode SyncMode
+	maxSize  int64
+	lastSync time.Time
+	stopCh   chan struct{}
+}
+
+func openJournal(path string, opts JournalOptions) (*journal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat journal: %v", err)
+	}
+	return &journal{
+		file:     f,
+		path:     path,
+		size:     info.Size(),
+		syncMode: opts.SyncMode,
+		maxSize:  opts.MaxSize,
+		stopCh:   make(chan struct{}),
+	}, nil
+}
+
+// append writes one record for op/payload, assigning it the next
+// sequence number, and syncs according to j.syncMode.
+func (j *journal) append(op journalOp, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal payload: %v", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	record := make([]byte, journalRecordHeaderSize+len(data))
+	binary.BigEndian.PutUint64(record[0:8], j.seq)
+	binary.BigEndian.PutUint64(record[8:16], uint64(time.Now().UnixNano()))
+	record[16] = byte(op)
+	copy(record[17:], data)
+
+	frame := make([]byte, 4+len(record)+4)
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(record)))
+	copy(frame[4:], record)
+	binary.BigEndian.PutUint32(frame[4+len(record):], crc32.ChecksumIEEE(record))
+
+	n, err := j.file.Write(frame)
+	if err != nil {
+		return fmt.Errorf("failed to append journal record: %v", err)
+	}
+	j.size += int64(n)
+
+	switch j.syncMode.kind {
+	case syncInterval:
+		if time.Since(j.lastSync) < j.syncMode.interval {
+			return nil
+		}
+		fallthrough
+	case syncAlways:
+		j.lastSync = time.Now()
+		if err := j.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync journal: %v", err)
+		}
+	}
+	return nil
+}
+
+func (j *journal) currentSeq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// truncate discards the journal's contents after a snapshot has already
+// folded them in, so compaction doesn't let the file grow forever.
+func (j *journal) truncate() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate journal: %v", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek journal: %v", err)
+	}
+	j.size = 0
+	return nil
+}
+
+func (j *journal) close() error {
+	close(j.stopCh)
+	return j.file.Close()
+}
+
+// journalRecord is one decoded, checksum-verified entry read back from
+// the journal file by readJournal.
+type journalRecord struct {
+	Seq     uint64
+	Ts      time.Time
+	Op      journalOp
+	Payload []byte
+}
+
+// readJournal reads every intact record from r in order, stopping (not
+// erroring) at the first length, payload, or CRC that doesn't fully
+// check out - tolerating a torn write left by a crash mid-append without
+// losing any record that did finish flushing before it.
+func readJournal(r io.Reader) []journalRecord {
+	br := bufio.NewReader(r)
+	var records []journalRecord
+	for {
+		lenBuf := make([]byte, 4)
+		if _, err := io.ReadFull(br, lenBuf); err != nil {
+			return records
+		}
+		recLen := binary.BigEndian.Uint32(lenBuf)
+		if recLen < journalRecordHeaderSize {
+			return records
+		}
+
+		record := make([]byte, recLen)
+		if _, err := io.ReadFull(br, record); err != nil {
+			return records
+		}
+
+		crcBuf := make([]byte, 4)
+		if _, err := io.ReadFull(br, crcBuf); err != nil {
+			return records
+		}
+		if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(crcBuf) {
+			return records
+		}
+
+		records = append(records, journalRecord{
+			Seq:     binary.BigEndian.Uint64(record[0:8]),
+			Ts:      time.Unix(0, int64(binary.BigEndian.Uint64(record[8:16]))),
+			Op:      journalOp(record[16]),
+			Payload: record[journalRecordHeaderSize:],
+		})
+	}
+}
+
+// journalSnapshot is the gob-encoded compaction artifact written
+// alongside the journal: everything the journal up through Seq has
+// folded in, so replay can skip straight past it.
+type journalSnapshot struct {
+	Seq       uint64
+	NextToken uint64
+	States    map[string]*State
+	Locks     map[string]*tableLock
+}
+
+func snapshotPath(journalPath string) string {
+	return journalPath + ".snapshot"
+}
+
+func writeSnapshot(path string, snap journalSnapshot) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(snap); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSnapshot loads path's snapshot, if any. A missing or corrupt
+// snapshot both return a nil snapshot rather than an error - recovery
+// simply falls back to replaying the journal from the very start.
+func readSnapshot(path string) *journalSnapshot {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var snap journalSnapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return nil
+	}
+	return &snap
+}
+
+// NewMemoryManagerWithJournal returns a Manager backed by an
+// append-only journal at path (plus a path+".snapshot" compaction file
+// alongside it), replaying the newest valid snapshot plus the journal
+// tail past its sequence number to recover the state that existed
+// before the process last stopped. Every subsequent
+// CreateState/UpdateState/DeleteState/LockStateFenced/UnlockStateFenced
+// call appends a record before returning, so a later restart can
+// recover up to that point, and a background goroutine snapshots and
+// truncates the journal once it exceeds opts.MaxSize (when non-zero).
+func NewMemoryManagerWithJournal(path string, opts JournalOptions) (Manager, error) {
+	m := newMemoryManager()
+
+	var fromSeq uint64
+	if snap := readSnapshot(snapshotPath(path)); snap != nil {
+		m.states = snap.States
+		m.fenceLocks = snap.Locks
+		m.nextToken = snap.NextToken
+		fromSeq = snap.Seq
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal for replay: %v", err)
+	}
+	records := readJournal(f)
+	f.Close()
+
+	maxSeq := fromSeq
+	for _, rec := range records {
+		if rec.Seq <= fromSeq {
+			continue // Already folded into the snapshot.
+		}
+		if err := m.applyJournalRecord(rec); err != nil {
+			return nil, err
+		}
+		maxSeq = rec.Seq
+	}
+
+	j, err := openJournal(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	j.seq = maxSeq
+	m.journal = j
+
+	go m.janitor()
+	go m.compactor()
+	return m, nil
+}
+
+// applyJournalRecord replays one journal record into m's in-memory
+// maps during recovery.
+func (m *MemoryManager) applyJournalRecord(rec journalRecord) error {
+	switch rec.Op {
+	case opCreateState:
+		var p createStatePayload
+		if err := json.Unmarshal(rec.Payload, &p); err != nil {
+			return fmt.Errorf("failed to replay create: %v", err)
+		}
+		m.states[p.State.Table] = p.State
+
+	case opUpdateState:
+		var p updateStatePayload
+		if err := json.Unmarshal(rec.Payload, &p); err != nil {
+			return fmt.Errorf("failed to replay update: %v", err)
+		}
+		if s, ok := m.states[p.Table]; ok {
+			s.ProcessedRows = p.ProcessedRows
+			s.LastUpdated = rec.Ts
+		}
+
+	case opDeleteState:
+		var p deleteStatePayload
+		if err := json.Unmarshal(rec.Payload, &p); err != nil {
+			return fmt.Errorf("failed to replay delete: %v", err)
+		}
+		delete(m.states, p.Table)
+
+	case opLockState:
+		var p lockStatePayload
+		if err := json.Unmarshal(rec.Payload, &p); err != nil {
+			return fmt.Errorf("failed to replay lock: %v", err)
+		}
+		m.fenceLocks[p.Table] = &tableLock{token: p.Token, expiresAt: p.ExpiresAt}
+		if p.Token > m.nextToken {
+			m.nextToken = p.Token
+		}
+
+	case opUnlockState:
+		var p unlockStatePayload
+		if err := json.Unmarshal(rec.Payload, &p); err != nil {
+			return fmt.Errorf("failed to replay unlock: %v", err)
+		}
+		delete(m.fenceLocks, p.Table)
+	}
+	return nil
+}
+
+// compactionSweep is how often the compactor checks whether the journal
+// has crossed its configured MaxSize.
+const compactionSweep = time.Second
+
+func (m *MemoryManager) compactor() {
+	if m.journal.maxSize <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(compactionSweep)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.journal.stopCh:
+			return
+		case <-ticker.C:
+			m.maybeCompact()
+		}
+	}
+}
+
+// maybeCompact snapshots the current states/locks and truncates the
+// journal if it has grown past m.journal.maxSize, so the journal doesn't
+// otherwise grow without bound for a long-lived process.
+func (m *MemoryManager) maybeCompact() {
+	m.journal.mu.Lock()
+	size := m.journal.size
+	m.journal.mu.Unlock()
+	if size < m.journal.maxSize {
+		return
+	}
+
+	m.mu.Lock()
+	snap := journalSnapshot{
+		Seq:       m.journal.currentSeq(),
+		NextToken: m.nextToken,
+		States:    cloneStates(m.states),
+		Locks:     cloneLocks(m.fenceLocks),
+	}
+	m.mu.Unlock()
+
+	if err := writeSnapshot(snapshotPath(m.journal.path), snap); err != nil {
+		return // Leave the journal as-is; it's still a valid recovery source on its own.
+	}
+	m.journal.truncate()
+}
+
+func cloneStates(states map[string]*State) map[string]*State {
+	out := make(map[string]*State, len(states))
+	for table, s := range states {
+		out[table] = snapshotState(s)
+	}
+	return out
+}
+
+func cloneLocks(locks map[string]*tableLock) map[string]*tableLock {
+	out := make(map[string]*tableLock, len(locks))
+	for table, lock := range locks {
+		copied := *lock
+		out[table] = &copied
+	}
+	return out
+}
@@ -4,21 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
-// KubernetesManager implements the Manager interface using Kubernetes ConfigMaps
+// kubeStatePrefix names the ConfigMaps Watch inspects; it mirrors the
+// fmt.Sprintf("sqlextract-state-%s", table) convention every other
+// method here uses.
+const kubeStatePrefix = "sqlextract-state-"
+
+// KubernetesManager implements the Manager interface using Kubernetes
+// ConfigMaps for state/manifests and coordination.k8s.io/v1 Leases for
+// locks, so a lock left behind by a dead job is automatically
+// reclaimable once its lease expires instead of being held forever.
 type KubernetesManager struct {
 	client    *kubernetes.Clientset
 	namespace string
+	ownerID   string
 }
 
-func NewKubernetesManager(namespace string) (*KubernetesManager, error) {
+// NewKubernetesManager creates a KubernetesManager using in-cluster
+// config. ownerID identifies this process's leases (as the Lease's
+// holderIdentity), so a renewal by the same replica can be told apart
+// from a lease held by another one.
+func NewKubernetesManager(namespace, ownerID string) (*KubernetesManager, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get in-cluster config: %v", err)
@@ -32,11 +48,11 @@ func NewKubernetesManager(namespace string) (*KubernetesManager, error) {
 	return &KubernetesManager{
 		client:    client,
 		namespace: namespace,
+		ownerID:   ownerID,
 	}, nil
 }
 
-func (k *KubernetesManager) GetState(table string) (*State, error) {
-	ctx := context.Background()
+func (k *KubernetesManager) GetState(ctx context.Context, table string) (*State, error) {
 	cm, err := k.client.CoreV1().ConfigMaps(k.namespace).Get(ctx, fmt.Sprintf("sqlextract-state-%s", table), metav1.GetOptions{})
 	if err != nil {
 		return nil, nil // Return nil if ConfigMap doesn't exist
@@ -50,9 +66,8 @@ func (k *KubernetesManager) GetState(table string) (*State, error) {
 	return &state, nil
 }
 
-func (k *KubernetesManager) UpdateState(table string, processedRows int64) error {
-	ctx := context.Background()
-	state, err := k.GetState(table)
+func (k *KubernetesManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	state, err := k.GetState(ctx, table)
 	if err != nil {
 		return err
 	}
@@ -93,8 +108,7 @@ func (k *KubernetesManager) UpdateState(table string, processedRows int64) error
 	return nil
 }
 
-func (k *KubernetesManager) CreateState(state *State) error {
-	ctx := context.Background()
+func (k *KubernetesManager) CreateState(ctx context.Context, state *State) error {
 	data, err := json.Marshal(state)
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %v", err)
@@ -117,8 +131,7 @@ func (k *KubernetesManager) CreateState(state *State) error {
 	return nil
 }
 
-func (k *KubernetesManager) DeleteState(jobID string) error {
-	ctx := context.Background()
+func (k *KubernetesManager) DeleteState(ctx context.Context, jobID string) error {
 	err := k.client.CoreV1().ConfigMaps(k.namespace).Delete(ctx, fmt.Sprintf("sqlextract-state-%s", jobID), metav1.DeleteOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete ConfigMap: %v", err)
@@ -127,8 +140,7 @@ func (k *KubernetesManager) DeleteState(jobID string) error {
 	return nil
 }
 
-func (k *KubernetesManager) ListStates() ([]*State, error) {
-	ctx := context.Background()
+func (k *KubernetesManager) ListStates(ctx context.Context) ([]*State, error) {
 	list, err := k.client.CoreV1().ConfigMaps(k.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: "app=sqlextract",
 	})
@@ -148,35 +160,481 @@ func (k *KubernetesManager) ListStates() ([]*State, error) {
 	return states, nil
 }
 
-func (k *KubernetesManager) LockState(jobID string, duration time.Duration) (bool, error) {
-	ctx := context.Background()
-	lockKey := fmt.Sprintf("sqlextract-lock-%s", jobID)
-	lock := &corev1.ConfigMap{
+// Watch streams StateEvents from a real client-go watch.Interface against
+// this namespace's ConfigMaps, so it reports changes any replica makes,
+// not just this one. Unlike ListStates, it doesn't filter by the
+// "app=sqlextract" label (never actually set on these ConfigMaps) and
+// instead recognizes state ConfigMaps by their sqlextract-state- name
+// prefix, the same convention GetState/CreateState/etc. key off of.
+func (k *KubernetesManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	w, err := k.client.CoreV1().ConfigMaps(k.namespace).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch ConfigMaps: %v", err)
+	}
+
+	out := make(chan StateEvent, watchHubBuffer)
+
+	go func() {
+		defer w.Stop()
+		defer close(out)
+
+		var seq uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+
+				cm, ok := ev.Object.(*corev1.ConfigMap)
+				if !ok || !strings.HasPrefix(cm.Name, kubeStatePrefix) {
+					continue
+				}
+				table := strings.TrimPrefix(cm.Name, kubeStatePrefix)
+
+				var kind EventKind
+				switch ev.Type {
+				case watch.Added:
+					kind = EventCreated
+				case watch.Modified:
+					kind = EventUpdated
+				case watch.Deleted:
+					kind = EventDeleted
+				default:
+					continue
+				}
+
+				var current *State
+				if kind != EventDeleted {
+					var s State
+					if err := json.Unmarshal([]byte(cm.Data["state"]), &s); err == nil {
+						current = &s
+					}
+				}
+
+				event := StateEvent{Table: table, Kind: kind, Current: current}
+				if !filter.matches(event) {
+					continue
+				}
+				seq++
+				event.Seq = seq
+
+				select {
+				case out <- event:
+				default:
+					// Subscriber's fallen behind; drop rather than block
+					// the watch-reading goroutine.
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (k *KubernetesManager) leaseName(jobID string) string {
+	return fmt.Sprintf("sqlextract-lock-%s", jobID)
+}
+
+// LockState creates, or takes over, a Lease named after jobID: it
+// succeeds if no Lease exists yet, the existing one's
+// renewTime+leaseDurationSeconds has already passed, or it's already
+// held by k.ownerID (renewal).
+func (k *KubernetesManager) LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	leases := k.client.CoordinationV1().Leases(k.namespace)
+
+	existing, err := leases.Get(ctx, k.leaseName(jobID), metav1.GetOptions{})
+	if err != nil {
+		return k.createLease(ctx, jobID, duration)
+	}
+
+	held := existing.Spec.HolderIdentity != nil && *existing.Spec.HolderIdentity == k.ownerID
+	if !held && !k.leaseExpired(existing) {
+		return false, nil
+	}
+
+	return k.takeOverLease(ctx, existing, held, duration)
+}
+
+func (k *KubernetesManager) createLease(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(duration.Seconds())
+	holder := k.ownerID
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: k.leaseName(jobID)},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+
+	if _, err := k.client.CoordinationV1().Leases(k.namespace).Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+		return false, nil // Lost the race to create it; treat as contended
+	}
+	return true, nil
+}
+
+// takeOverLease renews or reassigns existing, which LockState has
+// already determined is either held by k.ownerID or expired.
+func (k *KubernetesManager) takeOverLease(ctx context.Context, existing *coordinationv1.Lease, renewal bool, duration time.Duration) (bool, error) {
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(duration.Seconds())
+	holder := k.ownerID
+
+	if !renewal {
+		transitions := int32(1)
+		if existing.Spec.LeaseTransitions != nil {
+			transitions = *existing.Spec.LeaseTransitions + 1
+		}
+		existing.Spec.LeaseTransitions = &transitions
+		existing.Spec.AcquireTime = &now
+	}
+	existing.Spec.HolderIdentity = &holder
+	existing.Spec.LeaseDurationSeconds = &seconds
+	existing.Spec.RenewTime = &now
+
+	if _, err := k.client.CoordinationV1().Leases(k.namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return false, nil // Lost the race; another replica updated first
+	}
+	return true, nil
+}
+
+// leaseExpired reports whether lease's renewTime+leaseDurationSeconds
+// has passed, so a replica that died without releasing it doesn't hold
+// the lock forever.
+func (k *KubernetesManager) leaseExpired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(expiry)
+}
+
+// RenewLock extends jobID's Lease, if k.ownerID currently holds it. A
+// caller expecting its work on jobID to outlast one lease period should
+// call this roughly every leaseDuration/3 (see internal/extractor's
+// shard lease renewal goroutine).
+func (k *KubernetesManager) RenewLock(ctx context.Context, jobID string) error {
+	lease, err := k.client.CoordinationV1().Leases(k.namespace).Get(ctx, k.leaseName(jobID), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != k.ownerID {
+		return fmt.Errorf("lock for job ID %s is held by a different owner", jobID)
+	}
+
+	now := metav1.NewMicroTime(time.Now())
+	lease.Spec.RenewTime = &now
+	if _, err := k.client.CoordinationV1().Leases(k.namespace).Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to renew lease: %v", err)
+	}
+	return nil
+}
+
+// LockStatus reports jobID's current lease holder and expiry.
+func (k *KubernetesManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	lease, err := k.client.CoordinationV1().Leases(k.namespace).Get(ctx, k.leaseName(jobID), metav1.GetOptions{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return "", time.Time{}, fmt.Errorf("lease for job ID %s has no renewal info", jobID)
+	}
+
+	var holder string
+	if lease.Spec.HolderIdentity != nil {
+		holder = *lease.Spec.HolderIdentity
+	}
+	expiresAt := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return holder, expiresAt, nil
+}
+
+// UpdateQueryID persists the in-flight async query ID for a table
+func (k *KubernetesManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	state, err := k.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &State{
+			Table:       table,
+			LastUpdated: time.Now(),
+			Status:      "running",
+		}
+	}
+
+	state.QueryID = queryID
+	state.LastUpdated = time.Now()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sqlextract-state-%s", table),
+		},
+		Data: map[string]string{
+			"state": string(data),
+		},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateLastKey persists the last key-column tuple emitted by a
+// keyset-paginated ExtractBatch for a table
+func (k *KubernetesManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	state, err := k.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &State{
+			Table:       table,
+			LastUpdated: time.Now(),
+			Status:      "running",
+		}
+	}
+
+	state.LastKey = key
+	state.LastUpdated = time.Now()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: lockKey,
-			Annotations: map[string]string{
-				"expires": time.Now().Add(duration).Format(time.RFC3339),
-			},
+			Name: fmt.Sprintf("sqlextract-state-%s", table),
 		},
 		Data: map[string]string{
-			"locked_at": time.Now().Format(time.RFC3339),
+			"state": string(data),
 		},
 	}
 
-	_, err := k.client.CoreV1().ConfigMaps(k.namespace).Create(ctx, lock, metav1.CreateOptions{})
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
 	if err != nil {
-		return false, nil // Lock already exists
+		return fmt.Errorf("failed to update ConfigMap: %v", err)
 	}
 
-	return true, nil
+	return nil
 }
 
-func (k *KubernetesManager) UnlockState(jobID string) error {
-	ctx := context.Background()
-	lockKey := fmt.Sprintf("sqlextract-lock-%s", jobID)
-	err := k.client.CoreV1().ConfigMaps(k.namespace).Delete(ctx, lockKey, metav1.DeleteOptions{})
+// UpdateCDCPosition persists the change-data-capture cursor consumed
+// through the most recent ExtractCDC call for table.
+func (k *KubernetesManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	state, err := k.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &State{
+			Table:       table,
+			LastUpdated: time.Now(),
+			Status:      "running",
+		}
+	}
+
+	state.CDCPosition = position
+	state.LastUpdated = time.Now()
+
+	data, err := json.Marshal(state)
 	if err != nil {
-		return fmt.Errorf("failed to delete lock: %v", err)
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sqlextract-state-%s", table),
+		},
+		Data: map[string]string{
+			"state": string(data),
+		},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus persists status for table, along with errMsg when status
+// is "failed".
+func (k *KubernetesManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	state, err := k.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &State{
+			Table:       table,
+			LastUpdated: time.Now(),
+		}
+	}
+
+	state.Status = status
+	state.Error = errMsg
+	state.LastUpdated = time.Now()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sqlextract-state-%s", table),
+		},
+		Data: map[string]string{
+			"state": string(data),
+		},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+// RecordBatch appends bc to table's Batches and persists the resulting
+// state in its sqlextract-state-<table> ConfigMap.
+func (k *KubernetesManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	state, err := k.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	state.Batches = append(state.Batches, bc)
+	state.LastUpdated = time.Now()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sqlextract-state-%s", table),
+		},
+		Data: map[string]string{
+			"state": string(data),
+		},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (k *KubernetesManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	state, err := k.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	state.SchemaHash = hash
+	state.SchemaColumns = columns
+	state.LastUpdated = time.Now()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sqlextract-state-%s", table),
+		},
+		Data: map[string]string{
+			"state": string(data),
+		},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+// SaveManifest persists the manifest produced by the most recent
+// extraction of table as a sqlextract-manifest-<table> ConfigMap,
+// overwriting any manifest saved earlier.
+func (k *KubernetesManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sqlextract-manifest-%s", table),
+		},
+		Data: map[string]string{
+			"manifest": string(data),
+		},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	if err != nil {
+		// If update fails because ConfigMap doesn't exist, create it
+		_, err = k.client.CoreV1().ConfigMaps(k.namespace).Create(ctx, cm, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create ConfigMap: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// GetManifest retrieves the manifest saved for table, or nil if none has
+// been saved yet.
+func (k *KubernetesManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	cm, err := k.client.CoreV1().ConfigMaps(k.namespace).Get(ctx, fmt.Sprintf("sqlextract-manifest-%s", table), metav1.GetOptions{})
+	if err != nil {
+		return nil, nil // Return nil if ConfigMap doesn't exist
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(cm.Data["manifest"]), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+	}
+
+	return &manifest, nil
+}
+
+func (k *KubernetesManager) UnlockState(ctx context.Context, jobID string) error {
+	if err := k.client.CoordinationV1().Leases(k.namespace).Delete(ctx, k.leaseName(jobID), metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete lease: %v", err)
 	}
 
 	return nil
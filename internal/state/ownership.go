@@ -0,0 +1,152 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// campaignSweepDivisor is how much of a CampaignOwner lease a worker
+// waits between heartbeat/claim sweeps - every lease/campaignSweepDivisor,
+// matching the "refresh every lease/3" cadence CampaignOwner implementations
+// share.
+const campaignSweepDivisor = 3
+
+// ownerStaleFactor is how many multiples of a lease must pass since a
+// table's last heartbeat before another worker may claim it out from
+// under its current owner.
+const ownerStaleFactor = 2
+
+// ownerEntry is one table's current CampaignOwner claim: who holds it and
+// when they last renewed it.
+type ownerEntry struct {
+	ownerID       string
+	lastHeartbeat time.Time
+}
+
+// TableOwnershipCampaigner is implemented by Managers that can run the
+// DDL-owner election described by CampaignOwner. Not every backend can
+// offer this, so it's a separate, optional interface rather than a new
+// Manager method.
+type TableOwnershipCampaigner interface {
+	// CampaignOwner makes workerID compete for ownership of every table
+	// this Manager currently has state for; see the MemoryManager
+	// implementation below for the exact election rule.
+	CampaignOwner(ctx context.Context, workerID string, lease time.Duration) (owned <-chan []string, resign func(), err error)
+}
+
+// CampaignOwner makes this MemoryManager's caller compete for ownership
+// of every table it currently has state for, using the DDL-owner
+// pattern: a table is claimed by writing {workerID, now} over any entry
+// whose heartbeat is older than ownerStaleFactor*lease, and a held
+// table's heartbeat is refreshed every lease/campaignSweepDivisor. The
+// returned channel receives the full set of tables workerID owns each
+// time it changes; resign immediately releases every table this call
+// owns, letting another worker claim them without waiting out their
+// staleness window. Since a single MemoryManager is only ever shared by
+// goroutines within one process, this implementation is trivially
+// correct (there's no real contention to resolve) and exists mainly so
+// tests can exercise CampaignOwner without a Postgres or etcd backend.
+func (m *MemoryManager) CampaignOwner(ctx context.Context, workerID string, lease time.Duration) (<-chan []string, func(), error) {
+	if lease <= 0 {
+		return nil, nil, fmt.Errorf("state: lease must be positive")
+	}
+
+	out := make(chan []string, 1)
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+	resign := func() {
+		stopOnce.Do(func() {
+			close(stopCh)
+			m.mu.Lock()
+			for table, entry := range m.owners {
+				if entry.ownerID == workerID {
+					delete(m.owners, table)
+				}
+			}
+			m.mu.Unlock()
+		})
+	}
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(lease / campaignSweepDivisor)
+		defer ticker.Stop()
+
+		var owned []string
+		sweep := func() {
+			next := m.campaignSweepLocked(workerID, lease)
+			if equalSortedStrings(owned, next) {
+				return
+			}
+			owned = next
+			select {
+			case out <- owned:
+			case <-stopCh:
+			case <-ctx.Done():
+			}
+		}
+
+		sweep()
+		for {
+			select {
+			case <-ctx.Done():
+				resign()
+				return
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+
+	return out, resign, nil
+}
+
+// campaignSweepLocked claims any table with no owner, or a stale one, on
+// workerID's behalf, refreshes the heartbeat of every table workerID
+// already owns, and returns the sorted set of tables it owns afterward.
+func (m *MemoryManager) campaignSweepLocked(workerID string, lease time.Duration) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for table := range m.states {
+		entry, claimed := m.owners[table]
+		switch {
+		case !claimed:
+			m.owners[table] = &ownerEntry{ownerID: workerID, lastHeartbeat: now}
+		case entry.ownerID == workerID:
+			entry.lastHeartbeat = now
+		case now.Sub(entry.lastHeartbeat) > ownerStaleFactor*lease:
+			m.owners[table] = &ownerEntry{ownerID: workerID, lastHeartbeat: now}
+		}
+	}
+
+	owned := make([]string, 0, len(m.owners))
+	for table, entry := range m.owners {
+		if entry.ownerID == workerID {
+			owned = append(owned, table)
+		}
+	}
+	sort.Strings(owned)
+	return owned
+}
+
+// equalSortedStrings reports whether a and b contain the same elements
+// in the same order, treating a nil and empty slice as equal.
+func equalSortedStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
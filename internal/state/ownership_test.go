@@ -0,0 +1,60 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryManager_CampaignOwner(t *testing.T) {
+	manager := NewMemoryManager().(*MemoryManager)
+	defer manager.Close()
+
+	ctx := context.Background()
+	if err := manager.CreateState(ctx, &State{Table: "owned_table"}); err != nil {
+		t.Fatalf("CreateState failed: %v", err)
+	}
+
+	campaignCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	owned, resign, err := manager.CampaignOwner(campaignCtx, "worker-1", 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CampaignOwner failed: %v", err)
+	}
+	defer resign()
+
+	select {
+	case tables := <-owned:
+		if len(tables) != 1 || tables[0] != "owned_table" {
+			t.Errorf("Expected [owned_table], got %v", tables)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for initial ownership set")
+	}
+
+	// A second worker shouldn't be able to claim the table while worker-1
+	// keeps refreshing it.
+	owned2, resign2, err := manager.CampaignOwner(campaignCtx, "worker-2", 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CampaignOwner failed: %v", err)
+	}
+	defer resign2()
+
+	select {
+	case tables := <-owned2:
+		t.Errorf("Expected worker-2 to not claim an actively-owned table, got %v", tables)
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	// Once worker-1 resigns, worker-2 should be able to claim the table.
+	resign()
+	select {
+	case tables := <-owned2:
+		if len(tables) != 1 || tables[0] != "owned_table" {
+			t.Errorf("Expected [owned_table], got %v", tables)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for worker-2 to claim the released table")
+	}
+}
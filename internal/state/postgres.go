@@ -0,0 +1,528 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgresManager implements the Manager interface against a shared
+// Postgres database, for multi-replica deployments that want a durable
+// store instead of per-process memory or a shared filesystem. Locks are
+// leader-election style leases: LockState only succeeds when no
+// unexpired lease exists for a jobID, or the caller already holds it
+// (renewal), with ownership tracked by ownerID alongside the expiry.
+type PostgresManager struct {
+	db      *sql.DB
+	connStr string
+	ownerID string
+}
+
+// pqNotifyChannel is the LISTEN/NOTIFY channel sqlextract_state's trigger
+// publishes to; Watch opens its own dedicated connection to it, since a
+// pooled *sql.DB connection can't hold a LISTEN session.
+const pqNotifyChannel = "sqlextract_state_changed"
+
+// NewPostgresManager opens connStr and ensures the tables PostgresManager
+// needs exist. ownerID identifies this process's leases, so a renewal by
+// the same replica can be told apart from a lease held by another one.
+func NewPostgresManager(connStr, ownerID string) (Manager, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %v", err)
+	}
+
+	m := &PostgresManager{db: db, connStr: connStr, ownerID: ownerID}
+	if err := m.ensureSchema(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *PostgresManager) ensureSchema() error {
+	_, err := m.db.Exec(`
+CREATE TABLE IF NOT EXISTS sqlextract_state (
+	table_name TEXT PRIMARY KEY,
+	data JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sqlextract_manifests (
+	table_name TEXT PRIMARY KEY,
+	data JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sqlextract_locks (
+	job_id TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL,
+	lease_seconds DOUBLE PRECISION NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sqlextract_ownership (
+	table_name TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL,
+	last_heartbeat TIMESTAMPTZ NOT NULL
+);
+CREATE OR REPLACE FUNCTION sqlextract_notify_state_change() RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('` + pqNotifyChannel + `',
+		TG_OP || ':' || COALESCE(NEW.table_name, OLD.table_name));
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS sqlextract_state_notify ON sqlextract_state;
+CREATE TRIGGER sqlextract_state_notify
+AFTER INSERT OR UPDATE OR DELETE ON sqlextract_state
+FOR EACH ROW EXECUTE FUNCTION sqlextract_notify_state_change();`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema: %v", err)
+	}
+	return nil
+}
+
+// Watch streams StateEvents built from Postgres LISTEN/NOTIFY
+// notifications sqlextract_state_notify emits on every insert, update,
+// and delete, so Watch sees changes any replica makes, not just this
+// one. Like RedisManager's keyspace-notification-backed Watch, the
+// trigger payload only carries which table changed and how (insert vs.
+// update vs. delete), not the row's new value, so Previous/Current are
+// always nil; a subscriber wanting the new state calls GetState(table).
+func (m *PostgresManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	out := make(chan StateEvent, watchHubBuffer)
+
+	listener := pq.NewListener(m.connStr, 2*time.Second, time.Minute, nil)
+	if err := listener.Listen(pqNotifyChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen for state changes: %v", err)
+	}
+
+	go func() {
+		defer listener.Close()
+		defer close(out)
+
+		var seq uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n, ok := <-listener.Notify:
+				if !ok {
+					return
+				}
+				if n == nil {
+					continue // Connection was re-established; nothing missed to replay.
+				}
+
+				op, table, found := strings.Cut(n.Extra, ":")
+				if !found {
+					continue
+				}
+
+				var kind EventKind
+				switch op {
+				case "INSERT":
+					kind = EventCreated
+				case "UPDATE":
+					kind = EventUpdated
+				case "DELETE":
+					kind = EventDeleted
+				default:
+					continue
+				}
+
+				event := StateEvent{Table: table, Kind: kind}
+				if !filter.matches(event) {
+					continue
+				}
+				seq++
+				event.Seq = seq
+
+				select {
+				case out <- event:
+				default:
+					// Subscriber's fallen behind; drop rather than block
+					// the notification-reading goroutine.
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (m *PostgresManager) GetState(ctx context.Context, table string) (*State, error) {
+	var data []byte
+	err := m.db.QueryRowContext(ctx, `SELECT data FROM sqlextract_state WHERE table_name = $1`, table).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %v", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %v", err)
+	}
+	return &s, nil
+}
+
+func (m *PostgresManager) saveState(ctx context.Context, s *State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+	_, err = m.db.ExecContext(ctx, `
+INSERT INTO sqlextract_state (table_name, data) VALUES ($1, $2)
+ON CONFLICT (table_name) DO UPDATE SET data = EXCLUDED.data`, s.Table, data)
+	if err != nil {
+		return fmt.Errorf("failed to save state: %v", err)
+	}
+	return nil
+}
+
+func (m *PostgresManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.ProcessedRows = processedRows
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *PostgresManager) CreateState(ctx context.Context, s *State) error {
+	return m.saveState(ctx, s)
+}
+
+func (m *PostgresManager) DeleteState(ctx context.Context, jobID string) error {
+	if _, err := m.db.ExecContext(ctx, `DELETE FROM sqlextract_state WHERE table_name = $1`, jobID); err != nil {
+		return fmt.Errorf("failed to delete state: %v", err)
+	}
+	return nil
+}
+
+func (m *PostgresManager) ListStates(ctx context.Context) ([]*State, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT data FROM sqlextract_state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %v", err)
+	}
+	defer rows.Close()
+
+	var states []*State
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan state: %v", err)
+		}
+		var s State
+		if err := json.Unmarshal(data, &s); err != nil {
+			continue // Skip invalid states
+		}
+		states = append(states, &s)
+	}
+	return states, nil
+}
+
+// LockState acquires, or renews, a leader-election style lease on jobID:
+// it succeeds if no unexpired lease exists, or the existing lease is
+// already owned by m.ownerID, and fails (without error) otherwise.
+func (m *PostgresManager) LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	res, err := m.db.ExecContext(ctx, `
+INSERT INTO sqlextract_locks (job_id, owner_id, lease_seconds, expires_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id) DO UPDATE SET owner_id = $2, lease_seconds = $3, expires_at = $4
+WHERE sqlextract_locks.owner_id = $2 OR sqlextract_locks.expires_at < now()`,
+		jobID, m.ownerID, duration.Seconds(), time.Now().Add(duration))
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check lock result: %v", err)
+	}
+	return affected > 0, nil
+}
+
+func (m *PostgresManager) UnlockState(ctx context.Context, jobID string) error {
+	if _, err := m.db.ExecContext(ctx, `DELETE FROM sqlextract_locks WHERE job_id = $1 AND owner_id = $2`, jobID, m.ownerID); err != nil {
+		return fmt.Errorf("failed to release lock: %v", err)
+	}
+	return nil
+}
+
+// RenewLock extends jobID's lease, if m.ownerID still holds it, using
+// the lease_seconds duration last passed to LockState for it.
+func (m *PostgresManager) RenewLock(ctx context.Context, jobID string) error {
+	var leaseSeconds float64
+	err := m.db.QueryRowContext(ctx, `SELECT lease_seconds FROM sqlextract_locks WHERE job_id = $1 AND owner_id = $2`, jobID, m.ownerID).Scan(&leaseSeconds)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read lock: %v", err)
+	}
+
+	duration := time.Duration(leaseSeconds * float64(time.Second))
+	if _, err := m.db.ExecContext(ctx, `UPDATE sqlextract_locks SET expires_at = $3 WHERE job_id = $1 AND owner_id = $2`,
+		jobID, m.ownerID, time.Now().Add(duration)); err != nil {
+		return fmt.Errorf("failed to renew lock: %v", err)
+	}
+	return nil
+}
+
+// LockStatus reports jobID's current lease holder and expiry.
+func (m *PostgresManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	var owner string
+	var expiresAt time.Time
+	err := m.db.QueryRowContext(ctx, `SELECT owner_id, expires_at FROM sqlextract_locks WHERE job_id = $1`, jobID).Scan(&owner, &expiresAt)
+	if err == sql.ErrNoRows {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read lock: %v", err)
+	}
+	return owner, expiresAt, nil
+}
+
+// UpdateQueryID persists the in-flight async query ID for a table
+func (m *PostgresManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.QueryID = queryID
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateLastKey persists the last key-column tuple emitted by a
+// keyset-paginated ExtractBatch for a table
+func (m *PostgresManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.LastKey = key
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateCDCPosition persists the change-data-capture cursor consumed
+// through the most recent ExtractCDC call for table.
+func (m *PostgresManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.CDCPosition = position
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateStatus persists status for table, along with errMsg when status
+// is "failed".
+func (m *PostgresManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table}
+	}
+	s.Status = status
+	s.Error = errMsg
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// RecordBatch appends bc to table's Batches.
+func (m *PostgresManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+	s.Batches = append(s.Batches, bc)
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (m *PostgresManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.SchemaHash = hash
+	s.SchemaColumns = columns
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// SaveManifest persists the manifest produced by the most recent
+// extraction of table, overwriting any manifest saved earlier.
+func (m *PostgresManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+	_, err = m.db.ExecContext(ctx, `
+INSERT INTO sqlextract_manifests (table_name, data) VALUES ($1, $2)
+ON CONFLICT (table_name) DO UPDATE SET data = EXCLUDED.data`, table, data)
+	if err != nil {
+		return fmt.Errorf("failed to save manifest: %v", err)
+	}
+	return nil
+}
+
+// GetManifest retrieves the manifest saved for table.
+func (m *PostgresManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	var data []byte
+	err := m.db.QueryRowContext(ctx, `SELECT data FROM sqlextract_manifests WHERE table_name = $1`, table).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no manifest found for table: %s", table)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+	}
+	return &manifest, nil
+}
+
+// CampaignOwner makes workerID compete for ownership of every table
+// sqlextract_state currently knows about, giving real cross-process
+// coordination (unlike MemoryManager's in-process version) via
+// sqlextract_ownership: a table is claimed by UPDATE ... WHERE owner_id =
+// $1 OR last_heartbeat < now() - 2*lease, the same conditional-update
+// idiom LockState uses, and a held table's heartbeat is refreshed every
+// lease/campaignSweepDivisor. The returned channel receives the full set
+// of tables workerID owns each time it changes; resign immediately
+// releases every table this call owns.
+func (m *PostgresManager) CampaignOwner(ctx context.Context, workerID string, lease time.Duration) (<-chan []string, func(), error) {
+	if lease <= 0 {
+		return nil, nil, fmt.Errorf("state: lease must be positive")
+	}
+
+	out := make(chan []string, 1)
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+	resign := func() {
+		stopOnce.Do(func() {
+			close(stopCh)
+			// Best effort: the worker is shutting down regardless, and
+			// ownerStaleFactor*lease bounds how long a failed delete here
+			// can block another worker from claiming these tables.
+			m.db.Exec(`DELETE FROM sqlextract_ownership WHERE owner_id = $1`, workerID)
+		})
+	}
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(lease / campaignSweepDivisor)
+		defer ticker.Stop()
+
+		var owned []string
+		sweep := func() {
+			next, err := m.campaignSweep(ctx, workerID, lease)
+			if err != nil || equalSortedStrings(owned, next) {
+				return
+			}
+			owned = next
+			select {
+			case out <- owned:
+			case <-stopCh:
+			case <-ctx.Done():
+			}
+		}
+
+		sweep()
+		for {
+			select {
+			case <-ctx.Done():
+				resign()
+				return
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+
+	return out, resign, nil
+}
+
+// campaignSweep claims every table in sqlextract_state with no
+// ownership row, or a stale one, on workerID's behalf, refreshes the
+// heartbeat of every table it already owns, and returns the sorted set
+// of tables it owns afterward.
+func (m *PostgresManager) campaignSweep(ctx context.Context, workerID string, lease time.Duration) ([]string, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT table_name FROM sqlextract_state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		if _, err := m.db.ExecContext(ctx, `
+INSERT INTO sqlextract_ownership (table_name, owner_id, last_heartbeat) VALUES ($1, $2, now())
+ON CONFLICT (table_name) DO UPDATE SET owner_id = $2, last_heartbeat = now()
+WHERE sqlextract_ownership.owner_id = $2
+   OR sqlextract_ownership.last_heartbeat < now() - ($3 || ' seconds')::interval`,
+			table, workerID, lease.Seconds()*ownerStaleFactor); err != nil {
+			return nil, fmt.Errorf("failed to campaign for table %s: %v", table, err)
+		}
+	}
+
+	ownRows, err := m.db.QueryContext(ctx, `SELECT table_name FROM sqlextract_ownership WHERE owner_id = $1 ORDER BY table_name`, workerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list owned tables: %v", err)
+	}
+	defer ownRows.Close()
+
+	var owned []string
+	for ownRows.Next() {
+		var table string
+		if err := ownRows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to scan owned table: %v", err)
+		}
+		owned = append(owned, table)
+	}
+	return owned, nil
+}
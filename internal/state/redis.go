@@ -0,0 +1,405 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisManager implements the Manager interface against Redis, for
+// deployments that already run Redis and want lower-latency state and
+// lock access than a round-trip to Postgres. Locks are leader-election
+// style leases implemented with SET NX PX, plus an owner-checked Lua
+// script for UnlockState so a lease can only be released by the replica
+// that currently holds it.
+type RedisManager struct {
+	client  *redis.Client
+	db      int
+	ownerID string
+}
+
+// NewRedisManager connects to addr (selecting logical database db) and
+// returns a RedisManager. ownerID identifies this process's leases, so a
+// renewal by the same replica can be told apart from a lease held by
+// another one.
+func NewRedisManager(addr, password string, db int, ownerID string) Manager {
+	return &RedisManager{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		db:      db,
+		ownerID: ownerID,
+	}
+}
+
+// Watch streams StateEvents built from Redis keyspace notifications on
+// this table's keys, requiring the server have notify-keyspace-events
+// configured to include at least "KEA" (or "Kg$x" for the specific
+// set/del/expired events used here). Unlike MemoryManager's Watch,
+// keyspace notifications only report that a key changed, not what its
+// new value is, so Previous/Current are always nil here - a subscriber
+// wanting the new state still needs GetState(table).
+func (m *RedisManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	prefix := fmt.Sprintf("__keyspace@%d__:sqlextract:state:", m.db)
+	pubsub := m.client.PSubscribe(ctx, prefix+"*")
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to keyspace notifications: %v", err)
+	}
+
+	out := make(chan StateEvent, watchHubBuffer)
+
+	go func() {
+		defer pubsub.Close()
+		defer close(out)
+
+		var seq uint64
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				var kind EventKind
+				switch msg.Payload {
+				case "set":
+					kind = EventUpdated
+				case "del", "expired":
+					kind = EventDeleted
+				default:
+					continue
+				}
+
+				event := StateEvent{Table: strings.TrimPrefix(msg.Channel, prefix), Kind: kind}
+				if !filter.matches(event) {
+					continue
+				}
+				seq++
+				event.Seq = seq
+
+				select {
+				case out <- event:
+				default:
+					// Subscriber's fallen behind; drop rather than block
+					// the notification-reading goroutine.
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (m *RedisManager) stateKey(table string) string {
+	return fmt.Sprintf("sqlextract:state:%s", table)
+}
+
+func (m *RedisManager) manifestKey(table string) string {
+	return fmt.Sprintf("sqlextract:manifest:%s", table)
+}
+
+func (m *RedisManager) lockKey(jobID string) string {
+	return fmt.Sprintf("sqlextract:lock:%s", jobID)
+}
+
+// lockDurationKey holds the lease duration LockState most recently used
+// for jobID, set with the same TTL as the lock itself, so RenewLock (and
+// LockStatus) don't need that duration threaded through their own
+// call sites.
+func (m *RedisManager) lockDurationKey(jobID string) string {
+	return fmt.Sprintf("sqlextract:lock:%s:duration", jobID)
+}
+
+func (m *RedisManager) GetState(ctx context.Context, table string) (*State, error) {
+	data, err := m.client.Get(ctx, m.stateKey(table)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %v", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %v", err)
+	}
+	return &s, nil
+}
+
+func (m *RedisManager) saveState(ctx context.Context, s *State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+	if err := m.client.Set(ctx, m.stateKey(s.Table), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save state: %v", err)
+	}
+	return nil
+}
+
+func (m *RedisManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.ProcessedRows = processedRows
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+func (m *RedisManager) CreateState(ctx context.Context, s *State) error {
+	return m.saveState(ctx, s)
+}
+
+func (m *RedisManager) DeleteState(ctx context.Context, jobID string) error {
+	if err := m.client.Del(ctx, m.stateKey(jobID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete state: %v", err)
+	}
+	return nil
+}
+
+func (m *RedisManager) ListStates(ctx context.Context) ([]*State, error) {
+	keys, err := m.client.Keys(ctx, "sqlextract:state:*").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %v", err)
+	}
+
+	var states []*State
+	for _, key := range keys {
+		data, err := m.client.Get(ctx, key).Bytes()
+		if err != nil {
+			continue
+		}
+		var s State
+		if err := json.Unmarshal(data, &s); err != nil {
+			continue // Skip invalid states
+		}
+		states = append(states, &s)
+	}
+	return states, nil
+}
+
+// LockState acquires, or renews, a leader-election style lease on jobID
+// using SET NX PX so only one replica holds it at a time; m.ownerID lets
+// the replica that already holds the lease renew it before it expires.
+func (m *RedisManager) LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error) {
+	key := m.lockKey(jobID)
+
+	ok, err := m.client.SetNX(ctx, key, m.ownerID, duration).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	if ok {
+		return true, m.setLockDuration(ctx, jobID, duration)
+	}
+
+	owner, err := m.client.Get(ctx, key).Result()
+	if err != nil {
+		return false, nil // Lock exists but vanished mid-check; treat as contended
+	}
+	if owner != m.ownerID {
+		return false, nil
+	}
+	if err := m.client.Set(ctx, key, m.ownerID, duration).Err(); err != nil {
+		return false, fmt.Errorf("failed to renew lock: %v", err)
+	}
+	return true, m.setLockDuration(ctx, jobID, duration)
+}
+
+func (m *RedisManager) setLockDuration(ctx context.Context, jobID string, duration time.Duration) error {
+	if err := m.client.Set(ctx, m.lockDurationKey(jobID), int64(duration), duration).Err(); err != nil {
+		return fmt.Errorf("failed to persist lease duration: %v", err)
+	}
+	return nil
+}
+
+// RenewLock extends jobID's lease, if m.ownerID still holds it, using
+// the duration last passed to LockState for it.
+func (m *RedisManager) RenewLock(ctx context.Context, jobID string) error {
+	owner, err := m.client.Get(ctx, m.lockKey(jobID)).Result()
+	if err == redis.Nil {
+		return fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read lock: %v", err)
+	}
+	if owner != m.ownerID {
+		return fmt.Errorf("lock for job ID %s is held by a different owner", jobID)
+	}
+
+	durationNanos, err := m.client.Get(ctx, m.lockDurationKey(jobID)).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to read lease duration: %v", err)
+	}
+	duration := time.Duration(durationNanos)
+
+	if err := m.client.Set(ctx, m.lockKey(jobID), m.ownerID, duration).Err(); err != nil {
+		return fmt.Errorf("failed to renew lock: %v", err)
+	}
+	return m.setLockDuration(ctx, jobID, duration)
+}
+
+// LockStatus reports jobID's current lease holder and expiry.
+func (m *RedisManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	owner, err := m.client.Get(ctx, m.lockKey(jobID)).Result()
+	if err == redis.Nil {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read lock: %v", err)
+	}
+
+	ttl, err := m.client.PTTL(ctx, m.lockKey(jobID)).Result()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read lock TTL: %v", err)
+	}
+	return owner, time.Now().Add(ttl), nil
+}
+
+// unlockScript deletes key only if its value still matches ARGV[1], so a
+// replica can never release a lease a different replica has since
+// acquired.
+const unlockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+else
+	return 0
+end`
+
+func (m *RedisManager) UnlockState(ctx context.Context, jobID string) error {
+	if err := m.client.Eval(ctx, unlockScript, []string{m.lockKey(jobID)}, m.ownerID).Err(); err != nil {
+		return fmt.Errorf("failed to release lock: %v", err)
+	}
+	return nil
+}
+
+// UpdateQueryID persists the in-flight async query ID for a table
+func (m *RedisManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.QueryID = queryID
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateLastKey persists the last key-column tuple emitted by a
+// keyset-paginated ExtractBatch for a table
+func (m *RedisManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.LastKey = key
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateCDCPosition persists the change-data-capture cursor consumed
+// through the most recent ExtractCDC call for table.
+func (m *RedisManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.CDCPosition = position
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateStatus persists status for table, along with errMsg when status
+// is "failed".
+func (m *RedisManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table}
+	}
+	s.Status = status
+	s.Error = errMsg
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// RecordBatch appends bc to table's Batches.
+func (m *RedisManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+	s.Batches = append(s.Batches, bc)
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (m *RedisManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	s, err := m.GetState(ctx, table)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &State{Table: table, Status: "running"}
+	}
+	s.SchemaHash = hash
+	s.SchemaColumns = columns
+	s.LastUpdated = time.Now()
+	return m.saveState(ctx, s)
+}
+
+// SaveManifest persists the manifest produced by the most recent
+// extraction of table, overwriting any manifest saved earlier.
+func (m *RedisManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+	if err := m.client.Set(ctx, m.manifestKey(table), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save manifest: %v", err)
+	}
+	return nil
+}
+
+// GetManifest retrieves the manifest saved for table.
+func (m *RedisManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	data, err := m.client.Get(ctx, m.manifestKey(table)).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("no manifest found for table: %s", table)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+	}
+	return &manifest, nil
+}
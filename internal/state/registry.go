@@ -0,0 +1,86 @@
+package state
+
+import "fmt"
+
+// Factory builds a Manager from a backend-specific configuration map, the
+// same free-form shape cmd/sqlextract already decodes per-backend flags
+// into before calling NewRedisManager/NewPostgresManager/etc. directly.
+// Keys are backend-specific; see each backend's New* constructor doc
+// comment for the ones it reads.
+type Factory func(cfg map[string]interface{}) (Manager, error)
+
+var factories = make(map[string]Factory)
+
+// Register adds a backend under name, so New can later build a Manager
+// from it by name alone instead of every caller importing and calling
+// that backend's New* constructor directly. Backends register themselves
+// from an init() in their own file; Register panics on a duplicate name,
+// the same way e.g. database/sql/driver.Register does, since that can
+// only happen from a programming mistake, not bad runtime input.
+func Register(name string, factory Factory) {
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("state: backend %q already registered", name))
+	}
+	factories[name] = factory
+}
+
+// New builds a Manager for the registered backend name, passing it cfg.
+func New(name string, cfg map[string]interface{}) (Manager, error) {
+	factory, exists := factories[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown state backend: %s", name)
+	}
+	return factory(cfg)
+}
+
+func cfgString(cfg map[string]interface{}, key, def string) string {
+	if v, ok := cfg[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func cfgInt(cfg map[string]interface{}, key string, def int) int {
+	if v, ok := cfg[key].(int); ok {
+		return v
+	}
+	return def
+}
+
+func init() {
+	Register("memory", func(cfg map[string]interface{}) (Manager, error) {
+		return NewMemoryManager(), nil
+	})
+	Register("redis", func(cfg map[string]interface{}) (Manager, error) {
+		ownerID := cfgString(cfg, "owner_id", "")
+		return NewRedisManager(
+			cfgString(cfg, "addr", "localhost:6379"),
+			cfgString(cfg, "password", ""),
+			cfgInt(cfg, "db", 0),
+			ownerID,
+		), nil
+	})
+	Register("postgres", func(cfg map[string]interface{}) (Manager, error) {
+		return NewPostgresManager(cfgString(cfg, "dsn", ""), cfgString(cfg, "owner_id", ""))
+	})
+	Register("kubernetes", func(cfg map[string]interface{}) (Manager, error) {
+		mgr, err := NewKubernetesManager(cfgString(cfg, "namespace", "default"), cfgString(cfg, "owner_id", ""))
+		if err != nil {
+			return nil, err
+		}
+		return mgr, nil
+	})
+	Register("bolt", func(cfg map[string]interface{}) (Manager, error) {
+		return NewBoltManager(
+			cfgString(cfg, "path", "sqlextract-state.db"),
+			cfgString(cfg, "owner_id", ""),
+		)
+	})
+	Register("etcd", func(cfg map[string]interface{}) (Manager, error) {
+		endpoints, _ := cfg["endpoints"].([]string)
+		if len(endpoints) == 0 {
+			endpoints = ParseEndpoints(cfgString(cfg, "dsn", "localhost:2379"))
+		}
+		return NewEtcdManager(endpoints, cfgString(cfg, "namespace", "sqlextract"), cfgString(cfg, "owner_id", ""))
+	})
+}
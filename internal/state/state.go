@@ -1,9 +1,13 @@
 package state
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // State represents the current state of a data extraction job
@@ -17,36 +21,288 @@ type State struct {
 	ProcessedRows int64
 	Status        string // "running", "completed", "failed"
 	Error         string
+
+	// QueryID holds the driver-native query identifier for a query that is
+	// still executing asynchronously (e.g. a Snowflake async query). It is
+	// cleared once the query's results have been fully consumed.
+	QueryID string
+
+	// LastKey holds the last key-column tuple emitted by a keyset
+	// (seek) paginated ExtractBatch, in key-column order. Drivers use it
+	// to generate "WHERE (k1, k2, ...) > (?, ?, ...)" instead of
+	// LIMIT/OFFSET, which avoids rescanning discarded rows on every batch.
+	LastKey []interface{}
+
+	// CDCPosition holds the driver-native change-data-capture cursor
+	// consumed through the most recent ExtractCDC call: a logical
+	// replication LSN for Postgres, or the LSN returned by
+	// sys.fn_cdc_get_max_lsn for MSSQL CDC. The next call resumes just
+	// past this position instead of re-reading changes already extracted.
+	CDCPosition string
+
+	// Batches records the per-batch checksums confirmed written to the
+	// output file so far, in write order. A restarted ExtractData resumes
+	// from just past the last entry here instead of from ProcessedRows,
+	// since ProcessedRows can point past rows that were counted but never
+	// actually landed on disk before a crash.
+	Batches []BatchChecksum
+
+	// SchemaHash holds the content-addressed hash a driver's
+	// GetTableSchemaHash computed for table as of the most recent run
+	// that recorded one, and SchemaColumns the column snapshot it was
+	// computed from. The next run compares its own hash against this one
+	// to detect that the source schema drifted (columns added, removed,
+	// or retyped) since the last extraction.
+	SchemaHash    string
+	SchemaColumns []ColumnSnapshot
 }
 
-// Manager defines the interface for state management
+// ColumnSnapshot is the state package's own copy of a single column's
+// name and declared type - duplicated from database.Column rather than
+// imported, since internal/database already imports internal/state and a
+// reverse import would cycle.
+type ColumnSnapshot struct {
+	Name string
+	Type string
+}
+
+// BatchChecksum records the integrity facts for one ExtractBatch page
+// written during extraction: where it started, how much it covered, and
+// a SHA-256 digest of the bytes written for it, so internal/verify can
+// recompute and compare a single batch without re-checksumming the whole
+// output.
+type BatchChecksum struct {
+	Offset        int64
+	RowCount      int64
+	ByteCount     int64
+	SHA256        string
+	KeyRangeStart []interface{}
+	KeyRangeEnd   []interface{}
+}
+
+// Manager defines the interface for state management. Every method takes
+// ctx as its first argument and must stop and return ctx.Err() once it's
+// been canceled or its deadline has passed, instead of completing
+// regardless - so a caller can bound a call with a timeout, cancel it on
+// SIGINT, or attach a tracing span, the same way the rest of the
+// extraction pipeline (Database.Exec, Database.ExtractArrow) already does.
 type Manager interface {
-	GetState(table string) (*State, error)
-	UpdateState(table string, processedRows int64) error
-	CreateState(state *State) error
-	DeleteState(jobID string) error
-	ListStates() ([]*State, error)
-	LockState(jobID string, duration time.Duration) (bool, error)
-	UnlockState(jobID string) error
+	GetState(ctx context.Context, table string) (*State, error)
+	UpdateState(ctx context.Context, table string, processedRows int64) error
+	CreateState(ctx context.Context, state *State) error
+	DeleteState(ctx context.Context, jobID string) error
+	ListStates(ctx context.Context) ([]*State, error)
+	LockState(ctx context.Context, jobID string, duration time.Duration) (bool, error)
+	UnlockState(ctx context.Context, jobID string) error
+	// RenewLock extends the lease LockState most recently acquired for
+	// jobID, on behalf of whoever's already holding it, without going
+	// through LockState's acquire-or-renew contention logic again. A
+	// caller that expects its own work on jobID to outlast one lease
+	// period should call this roughly every leaseDuration/3.
+	RenewLock(ctx context.Context, jobID string) error
+	// LockStatus reports the current holder and expiry of jobID's lease,
+	// or an error if no lease has been taken out for it.
+	LockStatus(ctx context.Context, jobID string) (holder string, expiresAt time.Time, err error)
+	// UpdateQueryID persists the driver-native ID of an in-flight async
+	// query so that a restarted process can re-attach to it instead of
+	// re-issuing the original query.
+	UpdateQueryID(ctx context.Context, table, queryID string) error
+	// UpdateLastKey persists the last key-column tuple emitted by a
+	// keyset-paginated ExtractBatch, so the next batch (or a resumed
+	// process) can seek from where it left off instead of using OFFSET.
+	UpdateLastKey(ctx context.Context, table string, key []interface{}) error
+	// UpdateCDCPosition persists the change-data-capture cursor consumed
+	// through the most recent ExtractCDC call for table, so the next
+	// call only fetches changes made since this position.
+	UpdateCDCPosition(ctx context.Context, table, position string) error
+	// UpdateStatus persists status ("running", "completed", or "failed")
+	// for table, along with errMsg when the extraction failed, so a
+	// restarted process can tell whether a previous extraction already
+	// finished (and can be skipped), is still in progress, or needs
+	// attention before it's resumed.
+	UpdateStatus(ctx context.Context, table, status, errMsg string) error
+	// SaveManifest persists the post-extract verification manifest most
+	// recently produced for table, replacing any manifest saved earlier.
+	SaveManifest(ctx context.Context, table string, manifest *Manifest) error
+	// GetManifest retrieves the manifest saved for table, or an error if
+	// none has been saved yet, so `sqlextract -verify` can recompute a
+	// fresh checksum and compare it against what was recorded at
+	// extraction time.
+	GetManifest(ctx context.Context, table string) (*Manifest, error)
+	// RecordBatch appends bc to table's state incrementally, as each
+	// batch is confirmed written, so a crash mid-extraction leaves behind
+	// a resumable record of exactly which batches already landed instead
+	// of just a row offset that a concurrent writer could have shifted
+	// out from under.
+	RecordBatch(ctx context.Context, table string, bc BatchChecksum) error
+	// UpdateSchemaSnapshot persists the column snapshot and hash a
+	// driver's GetTableSchemaHash computed for table during the run that
+	// just finished deciding whether it drifted, so the next run has
+	// something to compare its own snapshot against.
+	UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error
+	// Watch streams StateEvents matching filter until ctx is done, so a
+	// dashboard, orchestrator, or the CLI's --follow mode can react to
+	// create/update/delete/lock activity as it happens instead of
+	// polling ListStates on an interval.
+	Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error)
+}
+
+// ManifestPart describes one output file written during an extraction:
+// where it is, how many rows it holds, the key-column range it covers
+// (when keyColumns was given), and a checksum of the rows written to it.
+type ManifestPart struct {
+	Path     string
+	RowCount int64
+	MinKey   []interface{}
+	MaxKey   []interface{}
+	Checksum string
+	// Batches lists the per-batch SHA-256 digests recorded for this part,
+	// in write order, so internal/verify can recompute each one from the
+	// bytes actually on disk instead of only re-checksumming the source.
+	Batches []BatchChecksum
+}
+
+// Manifest records everything needed to verify one extraction of Table
+// after the fact: the parts written, the key columns and WHERE clause
+// used, and the checksum ExtractData computed against the source table
+// at extraction time (via Database.ChecksumTable). `sqlextract -verify`
+// re-runs ChecksumTable and compares it against SourceChecksum to detect
+// whether the source has changed, or the extraction was incomplete,
+// since this manifest was written.
+type Manifest struct {
+	Table       string
+	KeyColumns  string
+	WhereClause string
+	// Format is the output format ("csv" or "parquet") extraction wrote.
+	// internal/verify only knows how to recompute per-batch digests from
+	// a csv output file, so it checks this before attempting to.
+	Format         string
+	Parts          []ManifestPart
+	SourceChecksum string
+	CreatedAt      time.Time
 }
 
 // MemoryManager implements the Manager interface using in-memory storage
 // This is useful for testing and single-instance deployments
 type MemoryManager struct {
-	states map[string]*State
-	locks  map[string]time.Time
-	mu     sync.RWMutex
+	states        map[string]*State
+	locks         map[string]time.Time
+	lockDurations map[string]time.Duration
+	manifests     map[string]*Manifest
+	ownerID       string
+	mu            sync.RWMutex
+	hub           *watchHub
+	stopCh        chan struct{}
+
+	// waiters, fenceLocks, and nextToken back LockStateFenced/
+	// UnlockStateFenced; see fencing.go.
+	waiters    map[string][]*lockWaiter
+	fenceLocks map[string]*tableLock
+	nextToken  uint64
+
+	// queues, leases, and dead back Enqueue/Dequeue/ListDead; see
+	// batch_queue.go.
+	queues map[string]*batchQueue
+	leases map[uuid.UUID]*leasedBatch
+	dead   map[string][]*BatchEvent
+
+	// owners backs CampaignOwner; see ownership.go.
+	owners map[string]*ownerEntry
+
+	// journal is non-nil only for a MemoryManager constructed via
+	// NewMemoryManagerWithJournal; see journal.go.
+	journal *journal
 }
 
-// NewMemoryManager creates a new in-memory state manager
+// NewMemoryManager creates a new in-memory state manager. ownerID is
+// reported by LockStatus as the current lease holder; it has no bearing
+// on LockState's contention logic, since all callers sharing one
+// MemoryManager are already serialized by its mutex.
 func NewMemoryManager() Manager {
+	m := newMemoryManager()
+	go m.janitor()
+	return m
+}
+
+// newMemoryManager allocates a MemoryManager's maps without starting its
+// janitor goroutine, so NewMemoryManagerWithJournal can finish replaying
+// a journal before any background sweep touches m's state.
+func newMemoryManager() *MemoryManager {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
 	return &MemoryManager{
-		states: make(map[string]*State),
-		locks:  make(map[string]time.Time),
+		states:        make(map[string]*State),
+		locks:         make(map[string]time.Time),
+		lockDurations: make(map[string]time.Duration),
+		manifests:     make(map[string]*Manifest),
+		ownerID:       fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		hub:           newWatchHub(),
+		stopCh:        make(chan struct{}),
+		waiters:       make(map[string][]*lockWaiter),
+		fenceLocks:    make(map[string]*tableLock),
+		queues:        make(map[string]*batchQueue),
+		leases:        make(map[uuid.UUID]*leasedBatch),
+		dead:          make(map[string][]*BatchEvent),
+		owners:        make(map[string]*ownerEntry),
 	}
 }
 
-func (m *MemoryManager) GetState(table string) (*State, error) {
+// Close stops the background goroutine that expires stale fenced locks
+// and batch leases, along with the journal's compactor and underlying
+// file if this MemoryManager was constructed via
+// NewMemoryManagerWithJournal. Safe to call once a MemoryManager is no
+// longer needed; not part of the Manager interface since most callers
+// (the CLI, tests) never need to shut one down before the process exits.
+func (m *MemoryManager) Close() error {
+	close(m.stopCh)
+	if m.journal != nil {
+		return m.journal.close()
+	}
+	return nil
+}
+
+// lockExpirySweep is how often MemoryManager's janitor goroutine scans
+// for fenced locks and batch leases that expired without their holder
+// releasing them, freeing them up for the next waiter.
+const lockExpirySweep = 20 * time.Millisecond
+
+func (m *MemoryManager) janitor() {
+	ticker := time.NewTicker(lockExpirySweep)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.expireFenceLocks()
+			m.expireBatchLeases()
+		}
+	}
+}
+
+// Watch streams StateEvents matching filter until ctx is done.
+func (m *MemoryManager) Watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	return m.hub.watch(ctx, filter)
+}
+
+// snapshotState returns a shallow copy of s (or nil), so a StateEvent's
+// Previous/Current don't keep aliasing the live *State a later call
+// might mutate out from under a subscriber that hasn't read them yet.
+func snapshotState(s *State) *State {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func (m *MemoryManager) GetState(ctx context.Context, table string) (*State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -58,7 +314,11 @@ func (m *MemoryManager) GetState(table string) (*State, error) {
 	return state, nil
 }
 
-func (m *MemoryManager) UpdateState(table string, processedRows int64) error {
+func (m *MemoryManager) UpdateState(ctx context.Context, table string, processedRows int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -72,12 +332,23 @@ func (m *MemoryManager) UpdateState(table string, processedRows int64) error {
 		return fmt.Errorf("state is locked for table: %s", table)
 	}
 
+	previous := snapshotState(state)
 	state.ProcessedRows = processedRows
 	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	if m.journal != nil {
+		if err := m.journal.append(opUpdateState, updateStatePayload{Table: table, ProcessedRows: processedRows}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (m *MemoryManager) CreateState(state *State) error {
+func (m *MemoryManager) CreateState(ctx context.Context, state *State) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -86,23 +357,44 @@ func (m *MemoryManager) CreateState(state *State) error {
 	}
 
 	m.states[state.Table] = state
+	m.hub.publish(EventCreated, state.Table, nil, snapshotState(state))
+	if m.journal != nil {
+		if err := m.journal.append(opCreateState, createStatePayload{State: state}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (m *MemoryManager) DeleteState(table string) error {
+func (m *MemoryManager) DeleteState(ctx context.Context, table string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.states[table]; !exists {
+	state, exists := m.states[table]
+	if !exists {
 		return fmt.Errorf("state not found for table: %s", table)
 	}
 
 	delete(m.states, table)
 	delete(m.locks, table)
+	m.hub.publish(EventDeleted, table, snapshotState(state), nil)
+	if m.journal != nil {
+		if err := m.journal.append(opDeleteState, deleteStatePayload{Table: table}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (m *MemoryManager) ListStates() ([]*State, error) {
+func (m *MemoryManager) ListStates(ctx context.Context) ([]*State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -114,7 +406,11 @@ func (m *MemoryManager) ListStates() ([]*State, error) {
 	return states, nil
 }
 
-func (m *MemoryManager) LockState(table string, duration time.Duration) (bool, error) {
+func (m *MemoryManager) LockState(ctx context.Context, table string, duration time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -128,10 +424,16 @@ func (m *MemoryManager) LockState(table string, duration time.Duration) (bool, e
 	}
 
 	m.locks[table] = now.Add(duration)
+	m.lockDurations[table] = duration
+	m.hub.publish(EventLocked, table, nil, snapshotState(m.states[table]))
 	return true, nil
 }
 
-func (m *MemoryManager) UnlockState(table string) error {
+func (m *MemoryManager) UnlockState(ctx context.Context, table string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -140,5 +442,206 @@ func (m *MemoryManager) UnlockState(table string) error {
 	}
 
 	delete(m.locks, table)
+	delete(m.lockDurations, table)
+	m.hub.publish(EventUnlocked, table, nil, snapshotState(m.states[table]))
+	return nil
+}
+
+// RenewLock extends jobID's lease by the duration last passed to
+// LockState for it.
+func (m *MemoryManager) RenewLock(ctx context.Context, jobID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	duration, exists := m.lockDurations[jobID]
+	if !exists {
+		return fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+
+	m.locks[jobID] = time.Now().Add(duration)
+	return nil
+}
+
+// LockStatus reports jobID's current lease holder and expiry.
+func (m *MemoryManager) LockStatus(ctx context.Context, jobID string) (string, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return "", time.Time{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	expiresAt, exists := m.locks[jobID]
+	if !exists {
+		return "", time.Time{}, fmt.Errorf("no lock found for job ID: %s", jobID)
+	}
+
+	return m.ownerID, expiresAt, nil
+}
+
+// UpdateQueryID persists the in-flight async query ID for a table
+func (m *MemoryManager) UpdateQueryID(ctx context.Context, table, queryID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[table]
+	if !exists {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.QueryID = queryID
+	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateLastKey persists the last key-column tuple emitted by a
+// keyset-paginated ExtractBatch for a table
+func (m *MemoryManager) UpdateLastKey(ctx context.Context, table string, key []interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[table]
+	if !exists {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.LastKey = key
+	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateCDCPosition persists the change-data-capture cursor consumed
+// through the most recent ExtractCDC call for table.
+func (m *MemoryManager) UpdateCDCPosition(ctx context.Context, table, position string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[table]
+	if !exists {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.CDCPosition = position
+	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateStatus persists status for table, along with errMsg when status
+// is "failed".
+func (m *MemoryManager) UpdateStatus(ctx context.Context, table, status, errMsg string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[table]
+	if !exists {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.Status = status
+	state.Error = errMsg
+	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// SaveManifest persists the manifest produced by the most recent
+// extraction of table, overwriting any manifest saved earlier.
+func (m *MemoryManager) SaveManifest(ctx context.Context, table string, manifest *Manifest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.manifests[table] = manifest
+	return nil
+}
+
+// GetManifest retrieves the manifest saved for table.
+func (m *MemoryManager) GetManifest(ctx context.Context, table string) (*Manifest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	manifest, exists := m.manifests[table]
+	if !exists {
+		return nil, fmt.Errorf("no manifest found for table: %s", table)
+	}
+
+	return manifest, nil
+}
+
+// RecordBatch appends bc to table's Batches.
+func (m *MemoryManager) RecordBatch(ctx context.Context, table string, bc BatchChecksum) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[table]
+	if !exists {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.Batches = append(state.Batches, bc)
+	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
+	return nil
+}
+
+// UpdateSchemaSnapshot persists the column snapshot and hash a driver's
+// GetTableSchemaHash computed for table.
+func (m *MemoryManager) UpdateSchemaSnapshot(ctx context.Context, table, hash string, columns []ColumnSnapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[table]
+	if !exists {
+		return fmt.Errorf("state not found for table: %s", table)
+	}
+
+	previous := snapshotState(state)
+	state.SchemaHash = hash
+	state.SchemaColumns = columns
+	state.LastUpdated = time.Now()
+	m.hub.publish(EventUpdated, table, previous, snapshotState(state))
 	return nil
 }
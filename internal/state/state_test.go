@@ -1,6 +1,7 @@
 package state
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 
 func TestMemoryManager(t *testing.T) {
 	manager := NewMemoryManager()
+	ctx := context.Background()
 
 	// Test basic state operations
 	t.Run("Basic Operations", func(t *testing.T) {
@@ -17,13 +19,13 @@ func TestMemoryManager(t *testing.T) {
 			LastUpdated: time.Now(),
 			Status:      "running",
 		}
-		err := manager.CreateState(state)
+		err := manager.CreateState(ctx, state)
 		if err != nil {
 			t.Errorf("Failed to create state: %v", err)
 		}
 
 		// Test GetState
-		got, err := manager.GetState("test_table")
+		got, err := manager.GetState(ctx, "test_table")
 		if err != nil {
 			t.Errorf("Failed to get state: %v", err)
 		}
@@ -32,11 +34,11 @@ func TestMemoryManager(t *testing.T) {
 		}
 
 		// Test UpdateState
-		err = manager.UpdateState("test_table", 100)
+		err = manager.UpdateState(ctx, "test_table", 100)
 		if err != nil {
 			t.Errorf("Failed to update state: %v", err)
 		}
-		got, err = manager.GetState("test_table")
+		got, err = manager.GetState(ctx, "test_table")
 		if err != nil {
 			t.Errorf("Failed to get updated state: %v", err)
 		}
@@ -45,11 +47,11 @@ func TestMemoryManager(t *testing.T) {
 		}
 
 		// Test DeleteState
-		err = manager.DeleteState("test_table")
+		err = manager.DeleteState(ctx, "test_table")
 		if err != nil {
 			t.Errorf("Failed to delete state: %v", err)
 		}
-		_, err = manager.GetState("test_table")
+		_, err = manager.GetState(ctx, "test_table")
 		if err == nil {
 			t.Error("Expected error when getting deleted state")
 		}
@@ -67,7 +69,7 @@ func TestMemoryManager(t *testing.T) {
 			LastUpdated: time.Now(),
 			Status:      "running",
 		}
-		err := manager.CreateState(state)
+		err := manager.CreateState(ctx, state)
 		if err != nil {
 			t.Fatalf("Failed to create state: %v", err)
 		}
@@ -78,7 +80,7 @@ func TestMemoryManager(t *testing.T) {
 			go func(routineID int) {
 				defer wg.Done()
 				for j := 0; j < numOperations; j++ {
-					err := manager.UpdateState("concurrent_table", int64(j+1))
+					err := manager.UpdateState(ctx, "concurrent_table", int64(j+1))
 					if err != nil {
 						t.Errorf("Failed to update state in goroutine %d: %v", routineID, err)
 					}
@@ -88,7 +90,7 @@ func TestMemoryManager(t *testing.T) {
 		wg.Wait()
 
 		// Verify final state
-		got, err := manager.GetState("concurrent_table")
+		got, err := manager.GetState(ctx, "concurrent_table")
 		if err != nil {
 			t.Errorf("Failed to get final state: %v", err)
 		}
@@ -105,13 +107,13 @@ func TestMemoryManager(t *testing.T) {
 			LastUpdated: time.Now(),
 			Status:      "running",
 		}
-		err := manager.CreateState(state)
+		err := manager.CreateState(ctx, state)
 		if err != nil {
 			t.Fatalf("Failed to create state: %v", err)
 		}
 
 		// Test LockState
-		locked, err := manager.LockState("test_table", 5*time.Second)
+		locked, err := manager.LockState(ctx, "test_table", 5*time.Second)
 		if err != nil {
 			t.Errorf("Failed to lock state: %v", err)
 		}
@@ -122,7 +124,7 @@ func TestMemoryManager(t *testing.T) {
 		// Test concurrent access to locked state
 		lockedChan := make(chan bool)
 		go func() {
-			err := manager.UpdateState("test_table", 200)
+			err := manager.UpdateState(ctx, "test_table", 200)
 			if err == nil {
 				lockedChan <- false
 			} else {
@@ -140,13 +142,13 @@ func TestMemoryManager(t *testing.T) {
 		}
 
 		// Test UnlockState
-		err = manager.UnlockState("test_table")
+		err = manager.UnlockState(ctx, "test_table")
 		if err != nil {
 			t.Errorf("Failed to unlock state: %v", err)
 		}
 
 		// Verify state can be updated after unlock
-		err = manager.UpdateState("test_table", 300)
+		err = manager.UpdateState(ctx, "test_table", 300)
 		if err != nil {
 			t.Errorf("Failed to update state after unlock: %v", err)
 		}
@@ -155,26 +157,26 @@ func TestMemoryManager(t *testing.T) {
 	// Test error cases
 	t.Run("Error Cases", func(t *testing.T) {
 		// Test getting non-existent state
-		_, err := manager.GetState("non_existent")
+		_, err := manager.GetState(ctx, "non_existent")
 		if err == nil {
 			t.Error("Expected error when getting non-existent state")
 		}
 
 		// Test updating non-existent state
-		err = manager.UpdateState("non_existent", 100)
+		err = manager.UpdateState(ctx, "non_existent", 100)
 		if err == nil {
 			t.Error("Expected error when updating non-existent state")
 		}
 
 		// Test deleting non-existent state
-		err = manager.DeleteState("non_existent")
+		err = manager.DeleteState(ctx, "non_existent")
 		if err == nil {
 			t.Error("Expected error when deleting non-existent state")
 		}
 
 		// Test locking non-existent state
 		var locked bool
-		locked, err = manager.LockState("non_existent", 5*time.Second)
+		locked, err = manager.LockState(ctx, "non_existent", 5*time.Second)
 		if err == nil {
 			t.Error("Expected error when locking non-existent state")
 		}
@@ -183,3 +185,93 @@ func TestMemoryManager(t *testing.T) {
 		}
 	})
 }
+
+// TestMemoryManagerContextCancellation verifies that a Manager call
+// in flight against an already-canceled context returns ctx.Err()
+// instead of completing as if nothing had happened.
+func TestMemoryManagerContextCancellation(t *testing.T) {
+	manager := NewMemoryManager()
+	bg := context.Background()
+
+	state := &State{
+		Table:       "cancel_table",
+		LastUpdated: time.Now(),
+		Status:      "running",
+	}
+	if err := manager.CreateState(bg, state); err != nil {
+		t.Fatalf("Failed to create state: %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(bg)
+	cancel()
+
+	if err := manager.UpdateState(canceled, "cancel_table", 100); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// The canceled call must not have mutated state.
+	got, err := manager.GetState(bg, "cancel_table")
+	if err != nil {
+		t.Fatalf("Failed to get state: %v", err)
+	}
+	if got.ProcessedRows != 0 {
+		t.Errorf("Expected canceled UpdateState to be a no-op, got ProcessedRows=%d", got.ProcessedRows)
+	}
+}
+
+// TestMemoryManagerWatch verifies Watch delivers a StateEvent for each
+// mutation, filtered by table and kind, and stops delivering once its
+// context is canceled.
+func TestMemoryManagerWatch(t *testing.T) {
+	manager := NewMemoryManager()
+	bg := context.Background()
+
+	watchCtx, cancel := context.WithCancel(bg)
+	events, err := manager.Watch(watchCtx, WatchFilter{TablePattern: "watch_table"})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	state := &State{Table: "watch_table", LastUpdated: time.Now(), Status: "running"}
+	if err := manager.CreateState(bg, state); err != nil {
+		t.Fatalf("CreateState failed: %v", err)
+	}
+	if err := manager.UpdateState(bg, "watch_table", 42); err != nil {
+		t.Fatalf("UpdateState failed: %v", err)
+	}
+	// A mutation against a different table must not be delivered.
+	if err := manager.CreateState(bg, &State{Table: "other_table", LastUpdated: time.Now()}); err != nil {
+		t.Fatalf("CreateState failed: %v", err)
+	}
+
+	wantKinds := []EventKind{EventCreated, EventUpdated}
+	for _, want := range wantKinds {
+		select {
+		case ev := <-events:
+			if ev.Kind != want {
+				t.Errorf("Expected event kind %v, got %v", want, ev.Kind)
+			}
+			if ev.Table != "watch_table" {
+				t.Errorf("Expected table watch_table, got %s", ev.Table)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %v event", want)
+		}
+	}
+
+	select {
+	case ev := <-events:
+		t.Errorf("Expected no event for other_table, got %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected channel to be closed after ctx cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for channel to close after ctx cancellation")
+	}
+}
@@ -0,0 +1,183 @@
+package state
+
+import (
+	"context"
+	"path"
+	"sync"
+)
+
+// EventKind identifies what happened to a table's state or lock in a
+// StateEvent.
+type EventKind int
+
+const (
+	// EventCreated fires when CreateState adds a table's state for the
+	// first time.
+	EventCreated EventKind = iota
+	// EventUpdated fires on any call that mutates an existing state:
+	// UpdateState, UpdateQueryID, UpdateLastKey, UpdateCDCPosition,
+	// UpdateStatus, RecordBatch, or UpdateSchemaSnapshot. Watch does not
+	// distinguish which of these fired; Previous/Current on the event
+	// carry enough to diff the fields a subscriber cares about.
+	EventUpdated
+	// EventDeleted fires when DeleteState removes a table's state.
+	EventDeleted
+	// EventLocked fires when LockState grants or renews a table's lock.
+	EventLocked
+	// EventUnlocked fires when UnlockState releases a table's lock, or
+	// a lease expires without one.
+	EventUnlocked
+	// EventOverflow is not tied to any real state change. A watcher
+	// receives it once, right after the subscriber channel has room
+	// again, whenever publishing events to it had to be dropped because
+	// its buffer was full - telling it that ListStates (or re-reading
+	// GetState for tables it cares about) is needed to recover a
+	// consistent view, since some events in between were lost.
+	EventOverflow
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventCreated:
+		return "created"
+	case EventUpdated:
+		return "updated"
+	case EventDeleted:
+		return "deleted"
+	case EventLocked:
+		return "locked"
+	case EventUnlocked:
+		return "unlocked"
+	case EventOverflow:
+		return "overflow"
+	default:
+		return "unknown"
+	}
+}
+
+// StateEvent describes one change a Manager's Watch stream reports. Seq
+// is monotonically increasing per Manager (not globally, and not
+// necessarily contiguous across separate Watch calls against the same
+// Manager), so a subscriber can tell events apart and detect the gap an
+// EventOverflow leaves behind. Previous is nil for EventCreated;
+// Current is nil for EventDeleted and for lock-only events
+// (EventLocked/EventUnlocked) against a table with no recorded State.
+type StateEvent struct {
+	Seq      uint64
+	Kind     EventKind
+	Table    string
+	Previous *State
+	Current  *State
+}
+
+// WatchFilter narrows a Watch call to the events a subscriber actually
+// wants. TablePattern is a path.Match-style glob matched against
+// StateEvent.Table ("orders", "orders_*", "*"); empty matches every
+// table. Kinds restricts which EventKinds are delivered; an empty slice
+// matches every kind (EventOverflow is always delivered regardless of
+// Kinds, since a subscriber needs it to know its view may be stale even
+// if it only asked for, say, EventLocked).
+type WatchFilter struct {
+	TablePattern string
+	Kinds        []EventKind
+}
+
+func (f WatchFilter) matches(e StateEvent) bool {
+	if f.TablePattern != "" {
+		if ok, err := path.Match(f.TablePattern, e.Table); err != nil || !ok {
+			return false
+		}
+	}
+	if e.Kind == EventOverflow || len(f.Kinds) == 0 {
+		return true
+	}
+	for _, k := range f.Kinds {
+		if k == e.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// watchHubBuffer is the default per-subscriber channel buffer a
+// watchHub gives a Watch call that didn't ask for a specific size.
+const watchHubBuffer = 64
+
+// watchHub is the in-process publish/subscribe core MemoryManager,
+// FileStateManager, and BoltManager all use to implement Watch: every
+// mutating call publishes one StateEvent after applying its change, and
+// every live subscriber gets a copy
+// (filtered, and dropped - with an EventOverflow sentinel once room
+// frees up again - if it's fallen behind). It's single-process only;
+// KubernetesManager, RedisManager, PostgresManager, and EtcdManager
+// instead build Watch on that backend's own native change-notification
+// mechanism so subscribers in a different process see the same events.
+type watchHub struct {
+	mu   sync.Mutex
+	subs map[*hubSubscriber]struct{}
+	seq  uint64
+}
+
+type hubSubscriber struct {
+	ch         chan StateEvent
+	filter     WatchFilter
+	overflowed bool
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{subs: make(map[*hubSubscriber]struct{})}
+}
+
+// watch registers filter and returns the channel StateEvents matching it
+// will be delivered to until ctx is done, at which point the channel is
+// closed and the subscription removed.
+func (h *watchHub) watch(ctx context.Context, filter WatchFilter) (<-chan StateEvent, error) {
+	sub := &hubSubscriber{ch: make(chan StateEvent, watchHubBuffer), filter: filter}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subs, sub)
+		h.mu.Unlock()
+		close(sub.ch)
+	}()
+
+	return sub.ch, nil
+}
+
+// publish delivers one StateEvent built from kind/table/previous/current
+// to every subscriber whose filter matches it, assigning it the next
+// sequence number. A subscriber whose buffer is full has the event
+// dropped rather than blocking the caller that triggered it; the next
+// event that does fit is preceded by one EventOverflow so the subscriber
+// knows to resynchronize.
+func (h *watchHub) publish(kind EventKind, table string, previous, current *State) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	event := StateEvent{Seq: h.seq, Kind: kind, Table: table, Previous: previous, Current: current}
+
+	for sub := range h.subs {
+		if !sub.filter.matches(event) {
+			continue
+		}
+		if sub.overflowed {
+			select {
+			case sub.ch <- StateEvent{Seq: h.seq, Kind: EventOverflow, Table: table}:
+				sub.overflowed = false
+			default:
+				continue // Still full; keep dropping until it drains.
+			}
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.overflowed = true
+		}
+	}
+}